@@ -13,30 +13,45 @@ import (
 
 type JSONAPIServer struct {
 	listenAddr             string
-	generator              generator.ScheduleGenerator
 	teacherController      controllers.TeacherController
 	studentGroupController controllers.StudentGroupController
 	lessonController       controllers.LessonController
+	scheduleController     controllers.GeneratedScheduleController
+	generatorController    controllers.GeneratorController
 }
 
 func NewJSONAPIServer(listenAddr string, cfg generator.ScheduleGeneratorConfig, db *gorm.DB) (*JSONAPIServer, error) {
-	gen, err := generator.NewScheduleGenerator(cfg)
+	// every job creates its own generator, this one only checks the config
+	_, err := generator.NewScheduleGenerator(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("can't create generator: %s", err.Error())
 	}
 
 	api := JSONAPIServer{
 		listenAddr: listenAddr,
-		generator:  *gen,
 	}
 
-	api.teacherController, err = controllers.NewDefaultTeacherController(db)
+	var teacherService services.TeacherService
+	api.teacherController, teacherService, err = controllers.NewDefaultTeacherController(db)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create teacher controller: %s", err)
 	}
 
-	api.studentGroupController = controllers.NewStudentGroupController(services.NewStudentGroupService([]types.StudentGroup{}))
-	api.lessonController = controllers.NewLessonController(services.NewLessonService([]types.Lesson{}))
+	var scheduleService services.GeneratedScheduleService
+	api.scheduleController, scheduleService, err = controllers.NewDefaultGeneratedScheduleController(db)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create generated schedule controller: %s", err)
+	}
+
+	studentGroupService := services.NewStudentGroupService([]types.StudentGroup{})
+	api.studentGroupController = controllers.NewStudentGroupController(studentGroupService)
+	api.generatorController = controllers.NewGeneratorController(
+		services.NewGeneratorJobService(cfg, teacherService, studentGroupService, scheduleService))
+	lessonService, err := services.NewLessonService([]types.Lesson{}, cfg, teacherService, studentGroupService)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create lesson service: %s", err)
+	}
+	api.lessonController = controllers.NewLessonController(lessonService)
 
 	return &api, nil
 }
@@ -44,9 +59,11 @@ func NewJSONAPIServer(listenAddr string, cfg generator.ScheduleGeneratorConfig,
 func (s *JSONAPIServer) Run() error {
 	server := gin.Default()
 
-	// server.POST("/generator/reset/", func(ctx *gin.Context) {
-
-	// })
+	generatorRouts := server.Group("/generator")
+	generatorRouts.POST("/jobs/", s.generatorController.CreateJob)
+	generatorRouts.GET("/jobs/:job_id/", s.generatorController.GetJob)
+	generatorRouts.GET("/jobs/:job_id/events/", s.generatorController.StreamJobEvents)
+	generatorRouts.POST("/jobs/:job_id/cancel/", s.generatorController.CancelJob)
 
 	teacherRouts := server.Group("/teacher")
 	teacherRouts.GET("/", s.teacherController.GetAll)
@@ -67,6 +84,11 @@ func (s *JSONAPIServer) Run() error {
 	lessonRouts.DELETE("/:lesson_id/", s.lessonController.Delete)
 	lessonRouts.POST("/swap/", s.lessonController.SwapSlots)
 
+	scheduleRouts := server.Group("/schedule")
+	scheduleRouts.GET("/", s.scheduleController.GetAll)
+	scheduleRouts.GET("/latest/", s.scheduleController.GetLatest)
+	scheduleRouts.GET("/:version/", s.scheduleController.GetVersion)
+
 	err := server.Run(s.listenAddr)
 	return err
 }
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Duckademic/schedule-generator/repositories"
+	"github.com/Duckademic/schedule-generator/services"
+	"github.com/Duckademic/schedule-generator/types"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GeneratedScheduleController interface {
+	GetAll(*gin.Context)
+	GetLatest(*gin.Context)
+	GetVersion(*gin.Context)
+}
+
+func NewGeneratedScheduleController(s services.GeneratedScheduleService) GeneratedScheduleController {
+	return &generatedScheduleController{service: s}
+}
+
+func NewDefaultGeneratedScheduleController(db *gorm.DB) (GeneratedScheduleController, services.GeneratedScheduleService, error) {
+	repo, err := repositories.NewGeneratedScheduleRepository(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create generated schedule repository: %s", err)
+	}
+
+	s, err := services.NewGORMGeneratedScheduleService(repo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create generated schedule service: %s", err)
+	}
+
+	return NewGeneratedScheduleController(s), s, nil
+}
+
+type generatedScheduleController struct {
+	service services.GeneratedScheduleService
+}
+
+func (gsc *generatedScheduleController) GetAll(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gsc.service.GetAll())
+}
+
+func (gsc *generatedScheduleController) GetLatest(ctx *gin.Context) {
+	schedule := gsc.service.FindLatest()
+	if schedule == nil {
+		types.ResponseWithError(ctx, http.StatusNotFound, fmt.Errorf("no generated schedules"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+func (gsc *generatedScheduleController) GetVersion(ctx *gin.Context) {
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil {
+		types.ResponseWithError(ctx, http.StatusBadRequest, fmt.Errorf("invalid version: %s", err))
+		return
+	}
+
+	schedule := gsc.service.FindVersion(version)
+	if schedule == nil {
+		types.ResponseWithError(ctx, http.StatusNotFound, fmt.Errorf("schedule version %d not found", version))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Duckademic/schedule-generator/services"
+	"github.com/Duckademic/schedule-generator/types"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GeneratorController interface {
+	CreateJob(*gin.Context)
+	GetJob(*gin.Context)
+	CancelJob(*gin.Context)       // stops the job, the best schedule found so far is saved
+	StreamJobEvents(*gin.Context) // streams progress events of the job over SSE until it finishes
+}
+
+func NewGeneratorController(s services.GeneratorJobService) GeneratorController {
+	return &generatorController{service: s}
+}
+
+type generatorController struct {
+	service services.GeneratorJobService
+}
+
+func (gc *generatorController) CreateJob(ctx *gin.Context) {
+	var input types.GeneratorInput
+	if err := ctx.ShouldBindBodyWithJSON(&input); err != nil {
+		types.ResponseWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	job, err := gc.service.Start(input)
+	if err != nil {
+		types.ResponseWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+func (gc *generatorController) GetJob(ctx *gin.Context) {
+	jobId, err := uuid.Parse(ctx.Param("job_id"))
+	if err != nil {
+		types.ResponseWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	job := gc.service.Find(jobId)
+	if job == nil {
+		types.ResponseWithError(ctx, http.StatusNotFound, fmt.Errorf("job %s not found", jobId))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+func (gc *generatorController) CancelJob(ctx *gin.Context) {
+	jobId, err := uuid.Parse(ctx.Param("job_id"))
+	if err != nil {
+		types.ResponseWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if gc.service.Find(jobId) == nil {
+		types.ResponseWithError(ctx, http.StatusNotFound, fmt.Errorf("job %s not found", jobId))
+		return
+	}
+	if err := gc.service.Cancel(jobId); err != nil {
+		types.ResponseWithError(ctx, http.StatusConflict, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gc.service.Find(jobId))
+}
+
+// StreamJobEvents sends all progress events of the job from its start, every event is named by its type.
+// The last event is "finished" with the job itself.
+func (gc *generatorController) StreamJobEvents(ctx *gin.Context) {
+	jobId, err := uuid.Parse(ctx.Param("job_id"))
+	if err != nil {
+		types.ResponseWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if gc.service.Find(jobId) == nil {
+		types.ResponseWithError(ctx, http.StatusNotFound, fmt.Errorf("job %s not found", jobId))
+		return
+	}
+
+	sent := 0
+	ctx.Stream(func(w io.Writer) bool {
+		events, finished, changed, ok := gc.service.WatchEvents(jobId, sent)
+		if !ok {
+			return false
+		}
+		for _, event := range events {
+			ctx.SSEvent(string(event.Type), event)
+		}
+		sent += len(events)
+		if len(events) != 0 {
+			return true
+		}
+		if finished {
+			ctx.SSEvent("finished", gc.service.Find(jobId))
+			return false
+		}
+
+		select {
+		case <-changed:
+			return true
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/Duckademic/schedule-generator/services"
 	"github.com/Duckademic/schedule-generator/types"
@@ -9,6 +11,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// LessonController moves and swaps lessons only if the edit doesn't break the rules of the generator.
+// An edit with the "override" query parameter is applied anyway. Both respond with the LessonEditResult,
+// a rejected edit with 409.
 type LessonController interface {
 	Controller[types.Lesson]
 	SwapSlots(*gin.Context)
@@ -31,6 +36,27 @@ type lessonController struct {
 	service services.LessonService
 }
 
+func (lc *lessonController) Update(ctx *gin.Context) {
+	lesson, err := lc.getObjectFromContext(ctx)
+	if err != nil {
+		types.ResponseWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	override, err := getOverride(ctx)
+	if err != nil {
+		types.ResponseWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := lc.service.Edit(*lesson, override)
+	if err != nil {
+		types.ResponseWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	responseWithEdit(ctx, result)
+}
+
 func (lc *lessonController) SwapSlots(ctx *gin.Context) {
 	type LessonPair struct {
 		First  uuid.UUID `json:"first" binding:"required"`
@@ -43,12 +69,35 @@ func (lc *lessonController) SwapSlots(ctx *gin.Context) {
 		types.ResponseWithError(ctx, http.StatusBadRequest, err)
 		return
 	}
+	override, err := getOverride(ctx)
+	if err != nil {
+		types.ResponseWithError(ctx, http.StatusBadRequest, err)
+		return
+	}
 
-	err = lc.service.SwapSlots(pair.First, pair.Second)
+	result, err := lc.service.SwapSlots(pair.First, pair.Second, override)
 	if err != nil {
 		types.ResponseWithError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	ctx.Status(http.StatusNoContent)
+	responseWithEdit(ctx, result)
+}
+
+// getOverride returns the "override" query parameter, false if it isn't set.
+func getOverride(ctx *gin.Context) (bool, error) {
+	override, err := strconv.ParseBool(ctx.DefaultQuery("override", "false"))
+	if err != nil {
+		return false, fmt.Errorf("invalid override: %s", err)
+	}
+	return override, nil
+}
+
+// responseWithEdit responds with the result of the edit, 409 if the edit is rejected.
+func responseWithEdit(ctx *gin.Context, result types.LessonEditResult) {
+	if !result.Applied {
+		ctx.JSON(http.StatusConflict, result)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
 }
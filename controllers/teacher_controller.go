@@ -25,18 +25,18 @@ func NewTeacherController(s services.TeacherService) TeacherController {
 	return &tc
 }
 
-func NewDefaultTeacherController(db *gorm.DB) (TeacherController, error) {
+func NewDefaultTeacherController(db *gorm.DB) (TeacherController, services.TeacherService, error) {
 	repo, err := repositories.NewTeacherRepository(db)
 	if err != nil {
-		return nil, fmt.Errorf("cannot crate teacher repository: %s", err)
+		return nil, nil, fmt.Errorf("cannot crate teacher repository: %s", err)
 	}
 
 	s, err := services.NewGORMTeacherService(repo)
 	if err != nil {
-		return nil, fmt.Errorf("cannot create teacher service: %s", err)
+		return nil, nil, fmt.Errorf("cannot create teacher service: %s", err)
 	}
 
-	return NewTeacherController(s), nil
+	return NewTeacherController(s), s, nil
 }
 
 type teacherController struct {
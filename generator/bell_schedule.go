@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Duckademic/schedule-generator/generator/entities"
+)
+
+// BellSchedule sets the clock times of the lesson slots.
+type BellSchedule struct {
+	Slots    []SlotTime     // times of the slots on every weekday
+	Weekdays [][]SlotTime   // times of the slots on the weekday (0 - Sunday), Slots if the weekday has none
+	Location *time.Location `json:"-"` // time zone of the semester, the location of the Start date if nil
+}
+
+// SlotTime is the start and the end of a slot, counted from the midnight.
+type SlotTime struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// IsSet returns true if the bell schedule has times of any slot.
+func (b *BellSchedule) IsSet() bool {
+	if len(b.Slots) != 0 {
+		return true
+	}
+	for _, slots := range b.Weekdays {
+		if len(slots) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the bell schedule that is set against the slots of every weekday (workLessons).
+// Returns an error if it is inconsistent or has no times for any working slot.
+func (b *BellSchedule) Validate(workLessons [][]float32) error {
+	if !b.IsSet() {
+		return nil
+	}
+	if len(b.Weekdays) != 0 && len(b.Weekdays) != 7 {
+		return fmt.Errorf("length of Weekdays %d instead of 7", len(b.Weekdays))
+	}
+
+	for weekday := range workLessons {
+		slots := b.getSlotTimes(weekday)
+		if len(slots) < len(workLessons[weekday]) {
+			return fmt.Errorf("weekday %d has %d slots, but times of %d", weekday, len(workLessons[weekday]), len(slots))
+		}
+		for i, slot := range slots {
+			if slot.Start < 0 || slot.End > 24*time.Hour {
+				return fmt.Errorf("weekday %d slot %d is outside of the day (%s-%s)", weekday, i, slot.Start, slot.End)
+			}
+			if slot.Start >= slot.End {
+				return fmt.Errorf("weekday %d slot %d starts (%s) after end (%s)", weekday, i, slot.Start, slot.End)
+			}
+			if i > 0 && slot.Start < slots[i-1].End {
+				return fmt.Errorf("weekday %d slot %d starts (%s) before the previous ends (%s)",
+					weekday, i, slot.Start, slots[i-1].End)
+			}
+		}
+	}
+	return nil
+}
+
+// getSlotTimes returns times of the slots on the weekday.
+func (b *BellSchedule) getSlotTimes(weekday int) []SlotTime {
+	if weekday >= 0 && weekday < len(b.Weekdays) && len(b.Weekdays[weekday]) != 0 {
+		return b.Weekdays[weekday]
+	}
+	return b.Slots
+}
+
+// getTimes returns the start and the end time of the slot of the date-indexed grid in the time zone (loc).
+// The day takes the bells of the weekday it works as.
+//
+// Returns an error if the calendar has no dates or the slot has no times.
+func (b *BellSchedule) getTimes(slot entities.LessonSlot, cal *entities.Calendar, loc *time.Location) (start, end time.Time, err error) {
+	if !cal.HasDates() {
+		return start, end, fmt.Errorf("grid days have no dates")
+	}
+	slots := b.getSlotTimes(cal.GetWeekday(slot.Day))
+	if slot.Slot < 0 || slot.Slot >= len(slots) {
+		return start, end, fmt.Errorf("slot %d/%d has no bell times", slot.Day, slot.Slot)
+	}
+
+	// the wall clock time is kept on days when the offset of the time zone changes
+	date := cal.GetDate(slot.Day)
+	start = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, int(slots[slot.Slot].Start), loc)
+	end = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, int(slots[slot.Slot].End), loc)
+	return start, end, nil
+}
+
+// getSlot returns the slot of the date-indexed grid that starts at the time (start), the time is taken
+// in the time zone (loc). It is the reverse of getTimes.
+//
+// Returns an error if the calendar has no dates or no slot starts at the time.
+func (b *BellSchedule) getSlot(start time.Time, cal *entities.Calendar, loc *time.Location) (entities.LessonSlot, error) {
+	if !cal.HasDates() {
+		return entities.LessonSlot{}, fmt.Errorf("grid days have no dates")
+	}
+
+	start = start.In(loc)
+	day := cal.GetDay(start)
+	clock := time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute +
+		time.Duration(start.Second())*time.Second + time.Duration(start.Nanosecond())
+	for i, slot := range b.getSlotTimes(cal.GetWeekday(day)) {
+		if slot.Start == clock {
+			return entities.NewLessonSlot(day, i), nil
+		}
+	}
+	return entities.LessonSlot{}, fmt.Errorf("no slot starts at %s", start.Format(time.DateTime))
+}
@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Duckademic/schedule-generator/generator/entities"
+)
+
+// CalendarConfig sets the semester dates that differ from the weekly timetable.
+type CalendarConfig struct {
+	Holidays  []time.Time   // non-working dates, all slots are blocked
+	ShortDays []ShortDay    // shortened working dates
+	Transfers []DayTransfer // dates that work as another weekday
+}
+
+// ShortDay is a working date with fewer slots than its weekday has.
+type ShortDay struct {
+	Date  time.Time
+	Slots int // number of the first slots that are available on the date
+}
+
+// DayTransfer is a date that follows the timetable, the lesson type bindings and the blocked-day rules
+// of another weekday (e.g. a Saturday that works as Monday).
+type DayTransfer struct {
+	Date    time.Time
+	Weekday int // weekday the date works as, 0 - Sunday
+}
+
+// Validate checks the calendar. Returns an error if it is inconsistent.
+func (c *CalendarConfig) Validate() error {
+	for i, transfer := range c.Transfers {
+		if transfer.Weekday < 0 || transfer.Weekday > 6 {
+			return fmt.Errorf("transfer %s has invalid weekday %d", transfer.Date.Format(time.DateOnly), transfer.Weekday)
+		}
+		date := entities.NewCalendar(transfer.Date, nil)
+		for _, other := range c.Transfers[:i] {
+			if date.GetDay(other.Date) == 0 {
+				return fmt.Errorf("%s is transferred twice", transfer.Date.Format(time.DateOnly))
+			}
+		}
+		for _, holiday := range c.Holidays {
+			if date.GetDay(holiday) == 0 {
+				return fmt.Errorf("%s is both holiday and transferred day", transfer.Date.Format(time.DateOnly))
+			}
+		}
+	}
+	for _, shortDay := range c.ShortDays {
+		if shortDay.Slots < 0 {
+			return fmt.Errorf("short day %s has slots below 0 (%d)", shortDay.Date.Format(time.DateOnly), shortDay.Slots)
+		}
+		date := entities.NewCalendar(shortDay.Date, nil)
+		for _, holiday := range c.Holidays {
+			if date.GetDay(holiday) == 0 {
+				return fmt.Errorf("%s is both holiday and short day", shortDay.Date.Format(time.DateOnly))
+			}
+		}
+	}
+	return nil
+}
+
+// newGridCalendar creates the calendar of the semester grid from the start to the end date.
+// Every date takes its own weekday unless it is transferred.
+func (c *CalendarConfig) newGridCalendar(start, end time.Time) *entities.Calendar {
+	weekdays := []int{}
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		weekdays = append(weekdays, int(date.Weekday()))
+	}
+
+	dates := entities.NewCalendar(start, nil)
+	for _, transfer := range c.Transfers {
+		if day := dates.GetDay(transfer.Date); day >= 0 && day < len(weekdays) {
+			weekdays[day] = transfer.Weekday
+		}
+	}
+	return entities.NewCalendar(start, weekdays)
+}
+
+// blockDays marks the slots of the date-indexed grid (grid) closed by the calendar as blocked.
+// The days of the grid follow the grid calendar (cal).
+func (c *CalendarConfig) blockDays(grid [][]float32, cal *entities.Calendar) {
+	for day := range grid {
+		for slot := range grid[day] {
+			if c.isClosed(entities.NewLessonSlot(day, slot), cal) {
+				grid[day][slot] = 0
+			}
+		}
+	}
+}
+
+// isClosed returns true if the slot of the date-indexed grid is closed by the calendar.
+// The days of the grid follow the grid calendar (cal).
+func (c *CalendarConfig) isClosed(slot entities.LessonSlot, cal *entities.Calendar) bool {
+	for _, holiday := range c.Holidays {
+		if cal.GetDay(holiday) == slot.Day {
+			return true
+		}
+	}
+	for _, shortDay := range c.ShortDays {
+		if cal.GetDay(shortDay.Date) == slot.Day && slot.Slot >= shortDay.Slots {
+			return true
+		}
+	}
+	return false
+}
+
+// CalendarShortfall describes a carcass lesson lost on a date closed by the calendar.
+// The MissingLessonsAdder makes the hours up on other days of the lesson type,
+// hours that can't be made up are reported with a MissingLessonsAdderError.
+type CalendarShortfall struct {
+	entities.UnassignedLesson
+	Date  time.Time // Closed date of the lost lesson.
+	Hours int       // Number of lost academic hours.
+}
@@ -0,0 +1,147 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/Duckademic/schedule-generator/generator/services"
+)
+
+// AnnealingConfig sets the temperature schedule and budgets of the simulated annealing.
+type AnnealingConfig struct {
+	InitialTemperature float64       // Starting temperature. Higher values accept worse moves more often.
+	CoolingRate        float64       // Multiplier applied to the temperature after every iteration (0 to 1].
+	MinTemperature     float64       // The search stops when the temperature falls below this value.
+	MaxIterations      int           // Iteration budget. 0 disables annealing.
+	TimeLimit          time.Duration // Time budget. 0 means no limit.
+}
+
+// IsEnabled returns true if annealing has an iteration budget.
+func (c *AnnealingConfig) IsEnabled() bool {
+	return c.MaxIterations > 0
+}
+
+// Validate checks the config of the enabled annealing. Returns an error if it is inconsistent.
+func (c *AnnealingConfig) Validate() error {
+	if !c.IsEnabled() {
+		return nil
+	}
+	if c.InitialTemperature <= 0 {
+		return fmt.Errorf("initial temperature below/equal to 0 (%f)", c.InitialTemperature)
+	}
+	if c.CoolingRate <= 0 || c.CoolingRate > 1 {
+		return fmt.Errorf("cooling rate %f outside of (0, 1]", c.CoolingRate)
+	}
+	if c.MinTemperature < 0 {
+		return fmt.Errorf("min temperature below 0 (%f)", c.MinTemperature)
+	}
+	if c.TimeLimit < 0 {
+		return fmt.Errorf("time limit below 0 (%s)", c.TimeLimit)
+	}
+	return nil
+}
+
+// Annealer improves a finished schedule with simulated annealing. A neighbour schedule is produced
+// by moving a random lesson to a random slot, the ScheduleFault is used as the energy.
+type Annealer interface {
+	OptimizerComponent      // Basic interface for optimizer component
+	Anneal(context.Context) // Runs annealing until any budget is exhausted or ctx is done, leaves the best found schedule
+}
+
+// NewAnnealer creates an Annealer instance.
+// It requires an ErrorService, annealing config, a LessonService, a function that rates the schedule
+// and the seed of random decisions.
+func NewAnnealer(
+	es ErrorService, cfg AnnealingConfig, ls services.LessonService, fault FaultEvaluator, seed int64,
+) Annealer {
+	return &annealer{
+		errorService:  es,
+		cfg:           cfg,
+		lessonService: ls,
+		fault:         fault,
+		random:        rand.New(rand.NewSource(seed)),
+		progress:      NewProgressReporter(nil),
+	}
+}
+
+type annealer struct {
+	errorService  ErrorService
+	cfg           AnnealingConfig
+	lessonService services.LessonService
+	fault         FaultEvaluator
+	random        *rand.Rand
+	progress      ProgressReporter
+	bestFault     float64
+	iterations    int
+}
+
+func (a *annealer) Anneal(ctx context.Context) {
+	lessons := a.lessonService.GetAll()
+	current := a.fault().Fault()
+	a.bestFault = current
+	a.progress.StartPhase(AnnealerPhase, a.cfg.MaxIterations)
+	defer func() { a.progress.FinishPhase(a.iterations) }()
+	if len(lessons) == 0 {
+		return
+	}
+
+	best := newLessonSnapshot(lessons)
+	started := time.Now()
+	temperature := a.cfg.InitialTemperature
+	for a.iterations = 0; a.iterations < a.cfg.MaxIterations; a.iterations++ {
+		if temperature < a.cfg.MinTemperature {
+			break
+		}
+		if a.cfg.TimeLimit > 0 && time.Since(started) > a.cfg.TimeLimit || ctx.Err() != nil {
+			break
+		}
+
+		lesson := lessons[a.random.Intn(len(lessons))]
+		from := lesson.LessonSlot
+		if a.lessonService.MoveLessonTo(lesson, randomSlot(a.random, &lesson.Teacher.BusyGrid)) == nil {
+			candidate := a.fault().Fault()
+			delta := candidate - current
+			if delta <= 0 || a.random.Float64() < math.Exp(-delta/temperature) {
+				current = candidate
+				if current < a.bestFault {
+					a.bestFault = current
+					best = newLessonSnapshot(lessons)
+				}
+			} else if err := a.lessonService.MoveLessonTo(lesson, from); err != nil {
+				// the lesson can't return, so the worse schedule is accepted
+				current = candidate
+			}
+		}
+
+		temperature *= a.cfg.CoolingRate
+		a.progress.ProgressWithFault(a.iterations, current)
+	}
+
+	if current > a.bestFault && best.restore(a.lessonService) != 0 {
+		a.bestFault = a.fault().Fault()
+	}
+}
+
+// Redirect to Anneal function
+func (a *annealer) Run(ctx context.Context) {
+	a.Anneal(ctx)
+}
+
+func (a *annealer) GetErrorService() ErrorService {
+	return a.errorService
+}
+
+func (a *annealer) SetProgressReporter(pr ProgressReporter) {
+	a.progress = pr
+}
+
+func (a *annealer) BestFault() float64 {
+	return a.bestFault
+}
+
+func (a *annealer) Iterations() int {
+	return a.iterations
+}
@@ -1,7 +1,18 @@
 package components
 
+import "context"
+
 // GeneratorComponent represents any component participating in the generation process.
 type GeneratorComponent interface {
-	Run()                          // The main improvement of schedule for generator
-	GetErrorService() ErrorService // Each component must embed an ErrorService to report generation errors.
+	Run(ctx context.Context)              // The main improvement of schedule for generator, stops early when ctx is done
+	GetErrorService() ErrorService        // Each component must embed an ErrorService to report generation errors.
+	SetProgressReporter(ProgressReporter) // Replaces the reporter of the component phase progress.
+}
+
+// OptimizerComponent represents a component that improves an already built schedule
+// and reports the result of its search.
+type OptimizerComponent interface {
+	GeneratorComponent  // Basic interface for generator component
+	BestFault() float64 // Returns the fault of the schedule left after the search.
+	Iterations() int    // Returns the number of performed iterations.
 }
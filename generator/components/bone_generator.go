@@ -1,6 +1,7 @@
 package components
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Duckademic/schedule-generator/generator/entities"
@@ -10,28 +11,36 @@ import (
 // BoneGenerator creates the initial weekly lesson structure (“bone week”)
 // by allocating lesson slots for groups and teachers in the first week.
 type BoneGenerator interface {
-	GeneratorComponent    // Basic interface for generator component
-	GenerateBoneLessons() // Add a BoneWeekError to ErrorService if at not enough space at bone week
+	GeneratorComponent // Basic interface for generator component
+	// Add a BoneWeekError to ErrorService if at not enough space at bone week.
+	// Stops when ctx is done, the remaining loads stay unassigned.
+	GenerateBoneLessons(ctx context.Context)
 }
 
 // NewBoneGenerator creates a BoneGenerator instance.
 // It requires an ErrorService, a list of study loads, a LessonService.
 func NewBoneGenerator(es ErrorService, l []*entities.UnassignedLesson, ls services.LessonService) BoneGenerator {
-	return &boneGenerator{errorService: es, loads: l, lessonService: ls}
+	return &boneGenerator{errorService: es, loads: l, lessonService: ls, progress: NewProgressReporter(nil)}
 }
 
 type boneGenerator struct {
 	errorService  ErrorService
 	loads         []*entities.UnassignedLesson
 	lessonService services.LessonService
+	progress      ProgressReporter
 }
 
 // GenerateBoneLessons allocates lesson slots for the bone week.
 // Uses brute force method, starts with teachers, then discipline and student groups,
 // then free slots for lesson type.
-func (bg *boneGenerator) GenerateBoneLessons() {
-	for _, load := range bg.loads {
-		teacher := load.Teacher
+func (bg *boneGenerator) GenerateBoneLessons(ctx context.Context) {
+	bg.progress.StartPhase(BoneGeneratorPhase, len(bg.loads))
+	defer bg.progress.FinishPhase(len(bg.loads))
+	for i, load := range bg.loads {
+		if ctx.Err() != nil {
+			return
+		}
+		bg.progress.Progress(i)
 		studentGroup := load.StudentGroup
 		lessonType := load.Type
 		// discipline := load.Discipline
@@ -42,14 +51,14 @@ func (bg *boneGenerator) GenerateBoneLessons() {
 		for !success {
 			// отримуємо доступний лекційний день
 			day := studentGroup.GetNextDayOfType(lessonType, offset)
-			if day > 7 || day < 0 {
+			if day < 0 {
 				// якщо день був не на кістковому тижні, виникає виняток, який треба обробити якось
 				bg.errorService.AddError(&BoneWeekError{UnassignedLesson: *load})
 				break
 			}
 
 			// отримання вільного слота для групи та викладача
-			lessonSlot := teacher.GetOptimalFreeSlot(studentGroup.GetFreeSlots(day), day)
+			lessonSlot := findOptimalSlot(bg.lessonService, load, day)
 
 			if lessonSlot != -1 {
 				slot := entities.LessonSlot{Day: day, Slot: lessonSlot}
@@ -71,14 +80,18 @@ func (bg *boneGenerator) GenerateBoneLessons() {
 }
 
 // Redirect to GenerateBoneLessons function
-func (bg *boneGenerator) Run() {
-	bg.GenerateBoneLessons()
+func (bg *boneGenerator) Run(ctx context.Context) {
+	bg.GenerateBoneLessons(ctx)
 }
 
 func (bg *boneGenerator) GetErrorService() ErrorService {
 	return bg.errorService
 }
 
+func (bg *boneGenerator) SetProgressReporter(pr ProgressReporter) {
+	bg.progress = pr
+}
+
 // BoneWeekError indicates that the BoneGenerator failed to allocate
 // enough space for lessons within the bone week.
 type BoneWeekError struct {
@@ -94,6 +107,22 @@ func (e *BoneWeekError) GetTypeOfError() GeneratorComponentErrorTypes {
 	return BoneWeekErrorType
 }
 
+// findOptimalSlot returns the optimal slot of the day that is free for the teacher and all student groups
+// of the load and has a free compatible room. Returns -1 if there is no such slot.
+func findOptimalSlot(ls services.LessonService, load *entities.UnassignedLesson, day int) int {
+	slots := load.GetFreeSlots(day)
+	for {
+		slot := load.Teacher.GetOptimalFreeSlot(slots, day)
+		if slot == -1 {
+			return -1
+		}
+		if _, err := ls.FindFreeRoom(*load, entities.NewLessonSlot(day, slot)); err == nil {
+			return slot
+		}
+		slots[slot] = 0
+	}
+}
+
 // FalseFreeSlotError indicates that slot is busy but algorithm determined it as free.
 type FalseFreeSlotError struct {
 	entities.UnassignedLesson
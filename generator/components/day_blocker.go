@@ -1,6 +1,7 @@
 package components
 
 import (
+	"context"
 	"fmt"
 	"slices"
 
@@ -10,13 +11,15 @@ import (
 // DayBlocker selects days for student groups
 type DayBlocker interface {
 	GeneratorComponent // Basic interface for generator component
-	SetDayTypes()      // Add a SetDayTypeError to ErrorService if at not enough days per group
+	// Add a SetDayTypeError to ErrorService if at not enough days per group.
+	// Stops when ctx is done, the remaining groups stay without day types.
+	SetDayTypes(ctx context.Context)
 }
 
 // NewDayBlocker creates a DayBlocker instance.
 // It requires an ErrorService and a list of student groups.
 func NewDayBlocker(studentGroups []*entities.StudentGroup, errorService ErrorService) DayBlocker {
-	db := dayBlocker{errorService: errorService}
+	db := dayBlocker{errorService: errorService, progress: NewProgressReporter(nil)}
 	db.setGroupExtensions(studentGroups)
 
 	return &db
@@ -51,17 +54,30 @@ func newGroupExtension(group *entities.StudentGroup) *groupExtension {
 type dayBlocker struct {
 	groupExtensions []groupExtension // StudentGroup collection
 	errorService    ErrorService     // Collection for errors
+	progress        ProgressReporter // Reporter of processed groups
 }
 
-func (db *dayBlocker) SetDayTypes() {
+func (db *dayBlocker) SetDayTypes(ctx context.Context) {
 	daysBlocked := make([]int, 7) // contains num of groups that chose this day
 
-	for _, group := range db.groupExtensions {
+	db.progress.StartPhase(DayBlockerPhase, len(db.groupExtensions))
+	defer db.progress.FinishPhase(len(db.groupExtensions))
+	for i, group := range db.groupExtensions {
+		if ctx.Err() != nil {
+			return
+		}
+		db.progress.Progress(i)
 		availableDays := []int{0, 1, 2, 3, 4, 5, 6}
 
 		for _, lt := range group.group.GetOwnLessonTypes() {
-			//select 2 days for every lesson type
-			for tmp_i := 0; tmp_i < 2; tmp_i++ { // break after error assigned
+			//select 2 days for every lesson type, days bound through connected groups are counted too
+			bound := 0
+			for day := range 7 {
+				if group.group.GetTypeOfDay(day) == lt {
+					bound++
+				}
+			}
+			for tmp_i := bound; tmp_i < 2; tmp_i++ { // break after error assigned
 				// select day that free and blocked the fewest times
 				min := 1000000000
 				mIndex := -1
@@ -103,9 +119,13 @@ func (db *dayBlocker) GetErrorService() ErrorService {
 	return db.errorService
 }
 
+func (db *dayBlocker) SetProgressReporter(pr ProgressReporter) {
+	db.progress = pr
+}
+
 // Redirect to SetDayTypes function
-func (db *dayBlocker) Run() {
-	db.SetDayTypes()
+func (db *dayBlocker) Run(ctx context.Context) {
+	db.SetDayTypes(ctx)
 }
 
 func (db *dayBlocker) setGroupExtensions(studentGroups []*entities.StudentGroup) {
@@ -113,8 +133,9 @@ func (db *dayBlocker) setGroupExtensions(studentGroups []*entities.StudentGroup)
 	for i := range studentGroups {
 		db.groupExtensions[i] = *newGroupExtension(studentGroups[i])
 	}
-	// sorts by connected groups count in decreasing order, and then by free day count in increasing order
-	slices.SortFunc(db.groupExtensions, func(a, b groupExtension) int {
+	// sorts by connected groups count in decreasing order, and then by free day count in increasing order,
+	// equal groups keep their order
+	slices.SortStableFunc(db.groupExtensions, func(a, b groupExtension) int {
 		if a.group.CountConnectedGroupsNumber() == b.group.CountConnectedGroupsNumber() {
 			if a.freeDayCount == b.freeDayCount {
 				return 0
@@ -0,0 +1,99 @@
+package components
+
+import (
+	"github.com/Duckademic/schedule-generator/generator/entities"
+)
+
+// NewTeacherWindowsParameter creates a ScheduleParameter with windows of every teacher.
+func NewTeacherWindowsParameter(ts TeacherReader, weight float64) ScheduleParameter {
+	contributions := make([]FaultContribution, 0, len(ts.GetAll()))
+	for _, teacher := range ts.GetAll() {
+		windows := teacher.GetWindows()
+		contribution := NewTeacherContribution(teacher, float64(len(windows)), nil)
+		contribution.Slots = windows
+		contributions = append(contributions, contribution)
+	}
+	return NewEntityScheduleParameter(contributions, weight)
+}
+
+// NewStudentGroupWindowsParameter creates a ScheduleParameter with windows of every student group.
+// Windows of subgroups are a part of their parent group windows.
+func NewStudentGroupWindowsParameter(sgs StudentGroupReader, weight float64) ScheduleParameter {
+	contributions := make([]FaultContribution, 0, len(sgs.GetAll()))
+	for _, studentGroup := range sgs.GetAll() {
+		if studentGroup.IsSubgroup() {
+			continue
+		}
+		windows := studentGroup.GetWindows()
+		contribution := NewStudentGroupContribution(studentGroup, float64(len(windows)), nil)
+		contribution.Slots = windows
+		contributions = append(contributions, contribution)
+	}
+	return NewEntityScheduleParameter(contributions, weight)
+}
+
+// NewTeacherHoursDeficitParameter creates a ScheduleParameter with missing study hours of every teacher.
+func NewTeacherHoursDeficitParameter(ts TeacherReader, weight float64) ScheduleParameter {
+	contributions := make([]FaultContribution, 0, len(ts.GetAll()))
+	for _, teacher := range ts.GetAll() {
+		contributions = append(contributions, NewTeacherContribution(teacher, float64(teacher.CountHourDeficit()), nil))
+	}
+	return NewEntityScheduleParameter(contributions, weight)
+}
+
+// NewStudentGroupHoursDeficitParameter creates a ScheduleParameter with missing study hours of every student group.
+func NewStudentGroupHoursDeficitParameter(sgs StudentGroupReader, weight float64) ScheduleParameter {
+	contributions := make([]FaultContribution, 0, len(sgs.GetAll()))
+	for _, studentGroup := range sgs.GetAll() {
+		contributions = append(contributions,
+			NewStudentGroupContribution(studentGroup, float64(studentGroup.CountHourDeficit()), nil))
+	}
+	return NewEntityScheduleParameter(contributions, weight)
+}
+
+// NewTeacherLessonOverlappingParameter creates a ScheduleParameter with overlapping lessons of every teacher.
+func NewTeacherLessonOverlappingParameter(ts TeacherReader, weight float64) ScheduleParameter {
+	contributions := make([]FaultContribution, 0, len(ts.GetAll()))
+	for _, teacher := range ts.GetAll() {
+		lessons := teacher.GetOverlappingLessons(teacher.GetAssignedLessons())
+		contributions = append(contributions, NewTeacherContribution(teacher, float64(len(lessons)), lessons))
+	}
+	return NewEntityScheduleParameter(contributions, weight)
+}
+
+// NewStudentGroupLessonOverlappingParameter creates a ScheduleParameter with overlapping lessons
+// of every student group.
+func NewStudentGroupLessonOverlappingParameter(sgs StudentGroupReader, weight float64) ScheduleParameter {
+	return newStudentGroupLessonsParameter(sgs, weight, func(sg *entities.StudentGroup) []*entities.Lesson {
+		return sg.GetOverlappingLessons(sg.GetAssignedLessons())
+	})
+}
+
+// NewStudentGroupOvertimeLessonsParameter creates a ScheduleParameter with lessons above the daily limit
+// of every student group.
+func NewStudentGroupOvertimeLessonsParameter(sgs StudentGroupReader, weight float64) ScheduleParameter {
+	return newStudentGroupLessonsParameter(sgs, weight, func(sg *entities.StudentGroup) []*entities.Lesson {
+		return sg.GetOvertimeLessons()
+	})
+}
+
+// NewStudentGroupInvalidLessonsByTypeParameter creates a ScheduleParameter with lessons on days
+// that are not allowed for their type.
+func NewStudentGroupInvalidLessonsByTypeParameter(sgs StudentGroupReader, weight float64) ScheduleParameter {
+	return newStudentGroupLessonsParameter(sgs, weight, func(sg *entities.StudentGroup) []*entities.Lesson {
+		return sg.GetInvalidLessonsByType()
+	})
+}
+
+// newStudentGroupLessonsParameter creates a ScheduleParameter that counts offending lessons
+// of every student group, returned by the function (offending).
+func newStudentGroupLessonsParameter(
+	sgs StudentGroupReader, weight float64, offending func(*entities.StudentGroup) []*entities.Lesson,
+) ScheduleParameter {
+	contributions := make([]FaultContribution, 0, len(sgs.GetAll()))
+	for _, studentGroup := range sgs.GetAll() {
+		lessons := offending(studentGroup)
+		contributions = append(contributions, NewStudentGroupContribution(studentGroup, float64(len(lessons)), lessons))
+	}
+	return NewEntityScheduleParameter(contributions, weight)
+}
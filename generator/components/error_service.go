@@ -2,6 +2,8 @@ package components
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 )
 
@@ -12,6 +14,8 @@ const (
 	SetDayTypeErrorType GeneratorComponentErrorTypes = iota
 	BoneWeekErrorType
 	MissingLessonsAdderErrorType
+	ExactBoneWeekErrorType
+	PinnedLessonErrorType
 
 	unexpectedErrorType = -1
 )
@@ -50,6 +54,7 @@ type ErrorService interface {
 	error                             // Implements the error interface; represents the final accumulated error.
 	AddError(GeneratorComponentError) // Add error to collection. The service automatically handles ordering or deduplication.
 	IsClear() bool                    // Returns true if no errors have been collected.
+	CountErrors() int                 // Returns the number of collected errors.
 }
 
 // NewErrorService creates new ErrorService instance
@@ -71,15 +76,24 @@ func (ec *errorService) AddError(err GeneratorComponentError) {
 func (ec *errorService) IsClear() bool {
 	return len(ec.errorMap) == 0
 }
+func (ec *errorService) CountErrors() (count int) {
+	for _, errs := range ec.errorMap {
+		count += len(errs)
+	}
+	return
+}
 func (ec *errorService) Error() string {
 	if len(ec.errorMap) == 0 {
 		return ""
 	}
 
+	// errors are grouped in the order of their types
+	keys := slices.Sorted(maps.Keys(ec.errorMap))
+
 	var b strings.Builder
-	for key, errs := range ec.errorMap {
+	for _, key := range keys {
 		b.WriteString(fmt.Sprintf("%d:\n", key))
-		for _, err := range errs {
+		for _, err := range ec.errorMap[key] {
 			b.WriteString(fmt.Sprintf("- %s\n", err.Error()))
 		}
 		b.WriteString("\n")
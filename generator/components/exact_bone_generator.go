@@ -0,0 +1,378 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Duckademic/schedule-generator/generator/entities"
+	"github.com/Duckademic/schedule-generator/generator/services"
+)
+
+// ExactBoneConfig sets the exact bone week solver.
+type ExactBoneConfig struct {
+	Enabled   bool // Replaces the greedy BoneGenerator with the exact solver.
+	NodeLimit int  // Maximum number of search nodes. 0 means no limit.
+}
+
+// IsEnabled returns true if the exact solver is selected.
+func (c *ExactBoneConfig) IsEnabled() bool {
+	return c.Enabled
+}
+
+// Validate checks the config of the exact solver. Returns an error if it is inconsistent.
+func (c *ExactBoneConfig) Validate() error {
+	if c.NodeLimit < 0 {
+		return fmt.Errorf("node limit below 0 (%d)", c.NodeLimit)
+	}
+	return nil
+}
+
+// NewExactBoneGenerator creates a BoneGenerator instance that places the bone week with backtracking
+// and forward checking over teacher and student groups grids. A partial placement is cut off when the remaining
+// loads of a student group exceed its free day capacity or can't close its windows within the daily limit.
+// It either finds a slot for every load or proves
+// that there is no such placement. In the second case (or when the node limit is reached) it adds
+// an ExactBoneWeekError and falls back to the greedy BoneGenerator.
+// Rooms aren't a branching decision: every assignment takes the smallest free compatible room,
+// so the search isn't a proof when rooms are used.
+//
+// It requires an ErrorService, exact solver config, a list of study loads, a LessonService.
+func NewExactBoneGenerator(es ErrorService, cfg ExactBoneConfig, l []*entities.UnassignedLesson, ls services.LessonService) BoneGenerator {
+	return &exactBoneGenerator{errorService: es, cfg: cfg, loads: l, lessonService: ls, progress: NewProgressReporter(nil)}
+}
+
+type exactBoneGenerator struct {
+	errorService  ErrorService
+	cfg           ExactBoneConfig
+	loads         []*entities.UnassignedLesson
+	lessonService services.LessonService
+	progress      ProgressReporter // reports the deepest placement of the search
+	deepest       int
+	slots         []entities.LessonSlot // assigned slot for each load, day -1 if not assigned
+	rooms         []*entities.Room      // assigned room for each load, nil if not assigned or rooms aren't used
+	nodes         int
+	aborted       bool
+	roomsUsed     bool
+}
+
+func (eg *exactBoneGenerator) GenerateBoneLessons(ctx context.Context) {
+	eg.slots = make([]entities.LessonSlot, len(eg.loads))
+	for i := range eg.slots {
+		eg.slots[i] = entities.NewLessonSlot(-1, -1)
+	}
+	eg.rooms = make([]*entities.Room, len(eg.loads))
+	eg.nodes = 0
+	eg.aborted = false
+	eg.roomsUsed = false
+	eg.deepest = 0
+
+	eg.progress.StartPhase(ExactBoneGeneratorPhase, len(eg.loads))
+	found := eg.search(ctx, 0)
+	eg.progress.FinishPhase(eg.deepest)
+	if found {
+		// the grids are released, so the lessons are assigned the usual way in chronological order,
+		// the placement is checked to have every lesson adjacent to the previous one of the group
+		for i := range eg.loads {
+			eg.mark(i, eg.slots[i], false)
+		}
+		for _, i := range eg.chronologicalOrder() {
+			if err := eg.lessonService.AssignLessonInRoom(*eg.loads[i], eg.slots[i], eg.rooms[i]); err != nil {
+				eg.errorService.AddError(NewUnexpectedError("slot is busy but solver determined it as free",
+					"exactBoneGenerator", "GenerateBoneLessons", &FalseFreeSlotError{
+						UnassignedLesson: *eg.loads[i],
+						slot:             eg.slots[i],
+						err:              err,
+					}))
+			}
+		}
+		return
+	}
+
+	eg.errorService.AddError(&ExactBoneWeekError{Proved: !eg.aborted && !eg.roomsUsed, Nodes: eg.nodes})
+	fallback := NewBoneGenerator(eg.errorService, eg.loads, eg.lessonService)
+	fallback.SetProgressReporter(eg.progress)
+	// the bone week is completed even if the search is canceled
+	fallback.GenerateBoneLessons(context.WithoutCancel(ctx))
+}
+
+// search assigns the remaining loads. Every node picks the load with the fewest available slots (MRV),
+// a load without slots means that the previous assignments can't be extended.
+//
+// Returns true if all loads are assigned. The grids stay marked with the found placement.
+func (eg *exactBoneGenerator) search(ctx context.Context, assigned int) bool {
+	if assigned > eg.deepest {
+		eg.deepest = assigned
+		eg.progress.Progress(assigned)
+	}
+	if assigned == len(eg.loads) {
+		return eg.hasNoWindows()
+	}
+
+	eg.nodes++
+	if eg.cfg.NodeLimit > 0 && eg.nodes > eg.cfg.NodeLimit || ctx.Err() != nil {
+		eg.aborted = true
+		return false
+	}
+
+	// forward checking: domains are recalculated after the previous assignment
+	selected := -1
+	var domain []entities.LessonSlot
+	for i := range eg.loads {
+		if eg.slots[i].Day != -1 {
+			continue
+		}
+		d := eg.getDomain(i)
+		if len(d) == 0 {
+			return false
+		}
+		if selected == -1 || len(d) < len(domain) {
+			selected, domain = i, d
+		}
+	}
+
+	for _, slot := range domain {
+		eg.mark(selected, slot, true)
+		eg.slots[selected] = slot
+		if eg.canComplete(selected) && eg.search(ctx, assigned+1) {
+			return true
+		}
+		eg.mark(selected, slot, false)
+		eg.slots[selected] = entities.NewLessonSlot(-1, -1)
+		if eg.aborted {
+			return false
+		}
+	}
+
+	return false
+}
+
+// getDomain returns the slots available to the load, the most comfortable first.
+func (eg *exactBoneGenerator) getDomain(load int) (domain []entities.LessonSlot) {
+	teacher := eg.loads[load].Teacher
+	studentGroup := eg.loads[load].StudentGroup
+	studentGroups := eg.loads[load].GetStudentGroups()
+	comfort := map[entities.LessonSlot]float32{}
+
+	for day := range studentGroup.Grid {
+		if slices.ContainsFunc(studentGroups, func(sg *entities.StudentGroup) bool {
+			return !sg.IsDayOfType(eg.loads[load].Type, day) || sg.CheckDayOverload(day)
+		}) {
+			continue
+		}
+		for slot := range studentGroup.Grid[day] {
+			ls := entities.NewLessonSlot(day, slot)
+			// windows aren't checked here, a later lesson can close the window of a partial placement
+			if !teacher.IsFree(ls) || slices.ContainsFunc(studentGroups, func(sg *entities.StudentGroup) bool {
+				return !sg.IsFree(ls)
+			}) {
+				continue
+			}
+			if _, err := eg.lessonService.FindFreeRoom(*eg.loads[load], ls); err != nil {
+				continue
+			}
+			domain = append(domain, ls)
+			comfort[ls] = teacher.Grid[day][slot] * studentGroup.Grid[day][slot]
+		}
+	}
+
+	slices.SortStableFunc(domain, func(a, b entities.LessonSlot) int {
+		if comfort[a] > comfort[b] {
+			return -1
+		} else if comfort[a] < comfort[b] {
+			return 1
+		}
+		return 0
+	})
+	return
+}
+
+// canComplete checks the bounds of the partial placement after the load (load) is assigned. For every grid
+// of its student groups (the subgroups of a group with subgroups) the remaining loads must fit into the free
+// capacity of the days, and the windows on the day of the assigned slot must be closable by the remaining loads
+// without exceeding the daily limit.
+func (eg *exactBoneGenerator) canComplete(load int) bool {
+	day := eg.slots[load].Day
+	for _, studentGroup := range eg.loads[load].GetStudentGroups() {
+		grids := studentGroup.GetSubgroups()
+		if len(grids) == 0 {
+			grids = []*entities.StudentGroup{studentGroup}
+		}
+		for _, grid := range grids {
+			remaining := eg.getRemainingLoads(grid)
+			if len(remaining) > countFreeCapacity(grid) || !eg.canCloseWindows(grid, day, remaining) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// getRemainingLoads returns the unassigned loads that take the slot in the grid of the student group (sg):
+// loads of the group and of its parent group.
+func (eg *exactBoneGenerator) getRemainingLoads(sg *entities.StudentGroup) (result []int) {
+	for i, load := range eg.loads {
+		if eg.slots[i].Day != -1 {
+			continue
+		}
+		if slices.ContainsFunc(load.GetStudentGroups(), func(group *entities.StudentGroup) bool {
+			return group == sg || sg.Parent != nil && group == sg.Parent
+		}) {
+			result = append(result, i)
+		}
+	}
+	return
+}
+
+// countFreeCapacity returns the number of lessons the student group (sg) can still get in its grid:
+// free slots of every day, but not more than the daily limit allows.
+func countFreeCapacity(sg *entities.StudentGroup) (count int) {
+	for day := range sg.Grid {
+		free := 0
+		for slot := range sg.Grid[day] {
+			if sg.BusyGrid.IsFree(entities.NewLessonSlot(day, slot)) {
+				free++
+			}
+		}
+		count += max(0, min(free, sg.MaxLessonsPerDay-sg.CountLessonsOn(day)))
+	}
+	return
+}
+
+// canCloseWindows checks if the windows of the student group (sg) on the day can be closed: every free slot
+// between its lessons needs a remaining load (from remaining) that can take it, there must be enough such loads,
+// and the day with the closed windows must stay within the daily limit.
+func (eg *exactBoneGenerator) canCloseWindows(sg *entities.StudentGroup, day int, remaining []int) bool {
+	first, last := -1, -1
+	for slot := range sg.Grid[day] {
+		if sg.IsLessonOn(entities.NewLessonSlot(day, slot)) {
+			if first == -1 {
+				first = slot
+			}
+			last = slot
+		}
+	}
+
+	windows := 0
+	closing := map[int]bool{}
+	for slot := first + 1; slot < last; slot++ {
+		ls := entities.NewLessonSlot(day, slot)
+		if !sg.BusyGrid.IsFree(ls) {
+			continue
+		}
+		windows++
+		closable := false
+		for _, i := range remaining {
+			if eg.canTake(i, ls) {
+				closing[i] = true
+				closable = true
+			}
+		}
+		if !closable {
+			return false
+		}
+	}
+	return len(closing) >= windows && sg.CountLessonsOn(day)+windows <= sg.MaxLessonsPerDay
+}
+
+// canTake checks if the unassigned load (load) can take the slot with the current grids, like getDomain does.
+func (eg *exactBoneGenerator) canTake(load int, slot entities.LessonSlot) bool {
+	if !eg.loads[load].Teacher.IsFree(slot) {
+		return false
+	}
+	return !slices.ContainsFunc(eg.loads[load].GetStudentGroups(), func(sg *entities.StudentGroup) bool {
+		return !sg.IsDayOfType(eg.loads[load].Type, slot.Day) || !sg.IsFree(slot)
+	})
+}
+
+// hasNoWindows checks if the complete placement can be assigned in chronological order without windows
+// of the student groups. The grids stay marked with the placement.
+func (eg *exactBoneGenerator) hasNoWindows() bool {
+	for i := range eg.loads {
+		eg.markStudentGroups(i, false)
+	}
+
+	valid := true
+	for _, i := range eg.chronologicalOrder() {
+		if valid && slices.ContainsFunc(eg.loads[i].GetStudentGroups(), func(sg *entities.StudentGroup) bool {
+			return sg.CheckGapOnAdd(eg.slots[i]) != nil
+		}) {
+			valid = false
+		}
+		eg.markStudentGroups(i, true)
+	}
+	return valid
+}
+
+// chronologicalOrder returns the indexes of the assigned loads sorted by their slots.
+func (eg *exactBoneGenerator) chronologicalOrder() []int {
+	order := make([]int, len(eg.loads))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		if eg.slots[a].After(eg.slots[b]) {
+			return 1
+		} else if eg.slots[b].After(eg.slots[a]) {
+			return -1
+		}
+		return 0
+	})
+	return order
+}
+
+// markStudentGroups sets the busy state of the assigned slot of the load in the student group grids only.
+func (eg *exactBoneGenerator) markStudentGroups(load int, isBusy bool) {
+	for _, studentGroup := range eg.loads[load].GetStudentGroups() {
+		studentGroup.SetSlotBusyState(eg.slots[load], isBusy)
+	}
+}
+
+// mark sets the busy state of the slot in the teacher, student groups and room grids of the load.
+// Marking a slot as busy takes a free room, releasing keeps the room to assign the lesson there later.
+func (eg *exactBoneGenerator) mark(load int, slot entities.LessonSlot, isBusy bool) {
+	eg.loads[load].Teacher.SetSlotBusyState(slot, isBusy)
+	for _, studentGroup := range eg.loads[load].GetStudentGroups() {
+		studentGroup.SetSlotBusyState(slot, isBusy)
+	}
+
+	if isBusy {
+		// the domain contains only slots with a free room
+		eg.rooms[load], _ = eg.lessonService.FindFreeRoom(*eg.loads[load], slot)
+	}
+	if eg.rooms[load] != nil {
+		eg.roomsUsed = true
+		eg.rooms[load].SetSlotBusyState(slot, isBusy)
+	}
+}
+
+// Redirect to GenerateBoneLessons function
+func (eg *exactBoneGenerator) Run(ctx context.Context) {
+	eg.GenerateBoneLessons(ctx)
+}
+
+func (eg *exactBoneGenerator) GetErrorService() ErrorService {
+	return eg.errorService
+}
+
+func (eg *exactBoneGenerator) SetProgressReporter(pr ProgressReporter) {
+	eg.progress = pr
+}
+
+// ExactBoneWeekError indicates that the exact solver didn't find a bone week placement for all loads.
+// Proved is true if the whole search space was explored, so there is no such placement.
+type ExactBoneWeekError struct {
+	Proved bool // The placement doesn't exist.
+	Nodes  int  // Number of explored search nodes.
+}
+
+func (e *ExactBoneWeekError) Error() string {
+	if e.Proved {
+		return fmt.Sprintf("bone week has no placement for all loads (proved with %d search nodes)", e.Nodes)
+	}
+	return fmt.Sprintf("bone week placement not found, search stopped after %d nodes", e.Nodes)
+}
+
+func (e *ExactBoneWeekError) GetTypeOfError() GeneratorComponentErrorTypes {
+	return ExactBoneWeekErrorType
+}
@@ -0,0 +1,151 @@
+package components
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Duckademic/schedule-generator/generator/entities"
+	"github.com/Duckademic/schedule-generator/generator/services"
+	"github.com/google/uuid"
+)
+
+// boneWeekFixture is a bone week with lectures on Monday only.
+type boneWeekFixture struct {
+	lecture    *entities.LessonType
+	discipline *entities.Discipline
+	loads      []*entities.UnassignedLesson
+}
+
+// mondayGrid returns a bone week grid with the slots (slots) on Monday, other days have no slots.
+func mondayGrid(slots ...float32) [][]float32 {
+	grid := make([][]float32, 7)
+	grid[1] = slots
+	return grid
+}
+
+func newBoneWeekFixture() *boneWeekFixture {
+	return &boneWeekFixture{
+		lecture:    &entities.LessonType{ID: uuid.New(), Name: "lecture", Value: 2},
+		discipline: entities.NewDiscipline(uuid.New(), "discipline"),
+	}
+}
+
+func (f *boneWeekFixture) newTeacher(t *testing.T, name string, grid [][]float32) *entities.Teacher {
+	t.Helper()
+	return entities.NewDefaultTeacher(uuid.New(), name, 0, entities.NewBusyGrid(grid, nil))
+}
+
+func (f *boneWeekFixture) newStudentGroup(t *testing.T, name string, grid [][]float32) *entities.StudentGroup {
+	t.Helper()
+	sg := entities.NewDefaultStudentGroup(uuid.New(), name, 4, entities.NewBusyGrid(grid, nil))
+	if err := sg.BindWeekday(f.lecture, 1); err != nil {
+		t.Fatalf("BindWeekday() error = %v", err)
+	}
+	return sg
+}
+
+// addLoad adds a load of one lecture a week.
+func (f *boneWeekFixture) addLoad(teacher *entities.Teacher, sg *entities.StudentGroup) {
+	teacher.AddLoad(entities.NewTeacherLoadKey(f.discipline, sg, f.lecture), 2)
+	sg.AddLoad(entities.NewStudentLoadKey(f.discipline, f.lecture, teacher), 2)
+	f.loads = append(f.loads, entities.NewUnassignedLesson(f.lecture, teacher, sg, f.discipline))
+}
+
+// run runs the exact solver with a time limit of 5 seconds and returns its lessons and the ExactBoneWeekError,
+// nil if the week is placed. A stopped search isn't a proof.
+func (f *boneWeekFixture) run(t *testing.T) ([]*entities.Lesson, *ExactBoneWeekError) {
+	t.Helper()
+	ls, err := services.NewLessonService(2, nil, nil)
+	if err != nil {
+		t.Fatalf("NewLessonService() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	es := NewErrorService()
+	NewExactBoneGenerator(es, ExactBoneConfig{Enabled: true}, f.loads, ls).Run(ctx)
+
+	var result *ExactBoneWeekError
+	for _, err := range es.(*errorService).errorMap[ExactBoneWeekErrorType] {
+		result = err.(*ExactBoneWeekError)
+	}
+	return ls.GetAll(), result
+}
+
+func TestExactBoneGeneratorProvesInfeasibility(t *testing.T) {
+	t.Run("teacher has one slot for two lessons", func(t *testing.T) {
+		f := newBoneWeekFixture()
+		teacher := f.newTeacher(t, "teacher", mondayGrid(1))
+		f.addLoad(teacher, f.newStudentGroup(t, "first", mondayGrid(1, 1)))
+		f.addLoad(teacher, f.newStudentGroup(t, "second", mondayGrid(1, 1)))
+
+		_, err := f.run(t)
+		if err == nil || !err.Proved {
+			t.Fatalf("ExactBoneWeekError = %+v, want a proved one", err)
+		}
+	})
+
+	t.Run("every placement leaves a window", func(t *testing.T) {
+		f := newBoneWeekFixture()
+		sg := f.newStudentGroup(t, "group", mondayGrid(1, 1, 1))
+		f.addLoad(f.newTeacher(t, "first", mondayGrid(1, 0, 0)), sg)
+		f.addLoad(f.newTeacher(t, "second", mondayGrid(0, 0, 1)), sg)
+
+		_, err := f.run(t)
+		if err == nil || !err.Proved {
+			t.Fatalf("ExactBoneWeekError = %+v, want a proved one", err)
+		}
+	})
+
+	// the instances below have millions of partial placements, the search must prune them to finish in time
+	t.Run("more lessons than the day limit", func(t *testing.T) {
+		f := newBoneWeekFixture()
+		sg := f.newStudentGroup(t, "group", mondayGrid(1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1))
+		sg.MaxLessonsPerDay = 8
+		for range 9 {
+			f.addLoad(f.newTeacher(t, "teacher", mondayGrid(1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1)), sg)
+		}
+
+		_, err := f.run(t)
+		if err == nil || !err.Proved {
+			t.Fatalf("ExactBoneWeekError = %+v, want a proved one", err)
+		}
+	})
+
+	t.Run("first and last lessons are too far apart for the day limit", func(t *testing.T) {
+		f := newBoneWeekFixture()
+		sg := f.newStudentGroup(t, "group", mondayGrid(1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1))
+		sg.MaxLessonsPerDay = 8
+		f.addLoad(f.newTeacher(t, "first", mondayGrid(1)), sg)
+		f.addLoad(f.newTeacher(t, "last", mondayGrid(0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1)), sg)
+		for range 6 {
+			f.addLoad(f.newTeacher(t, "teacher", mondayGrid(1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1)), sg)
+		}
+
+		_, err := f.run(t)
+		if err == nil || !err.Proved {
+			t.Fatalf("ExactBoneWeekError = %+v, want a proved one", err)
+		}
+	})
+}
+
+func TestExactBoneGeneratorClosesWindowsOfPartialPlacements(t *testing.T) {
+	// the lessons at the first and the last slot are placed first (fewest slots),
+	// the window between them is closed by the last placed lesson
+	f := newBoneWeekFixture()
+	sg := f.newStudentGroup(t, "group", mondayGrid(1, 1, 1))
+	f.addLoad(f.newTeacher(t, "first", mondayGrid(1, 0, 0)), sg)
+	f.addLoad(f.newTeacher(t, "last", mondayGrid(0, 0, 1)), sg)
+	f.addLoad(f.newTeacher(t, "any", mondayGrid(1, 1, 1)), sg)
+
+	lessons, err := f.run(t)
+	if err != nil {
+		t.Fatalf("ExactBoneWeekError = %v, want the week placed", err)
+	}
+	if len(lessons) != len(f.loads) {
+		t.Fatalf("got %d lessons, want %d", len(lessons), len(f.loads))
+	}
+	if windows := sg.CountWindows(); windows != 0 {
+		t.Errorf("student group has %d windows, want 0", windows)
+	}
+}
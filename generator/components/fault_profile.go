@@ -0,0 +1,97 @@
+package components
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Names of the built-in ScheduleFault parameters.
+const (
+	TeacherWindowsParameter                   = "teacher_windows"
+	StudentGroupWindowsParameter              = "student_group_windows"
+	TeacherHoursDeficitParameter              = "teacher_hours_deficit"
+	StudentGroupHoursDeficitParameter         = "student_group_hours_deficit"
+	TeacherLessonOverlappingParameter         = "teacher_lesson_overlapping"
+	StudentGroupLessonOverlappingParameter    = "student_group_lesson_overlapping"
+	StudentGroupOvertimeLessonsParameter      = "student_group_overtime_lessons"
+	StudentGroupInvalidLessonsByTypeParameter = "student_group_invalid_lessons_by_type"
+)
+
+// FaultProfile sets weights of the ScheduleFault parameters and turns them on or off by name.
+// The zero value is the default profile.
+type FaultProfile struct {
+	Name     string             `json:"name"`     // Human-readable identifier of the profile.
+	Weights  map[string]float64 `json:"weights"`  // Parameter name => weight. Overrides the default weights.
+	Disabled []string           `json:"disabled"` // Names of parameters excluded from the fault.
+}
+
+// GetWeight returns the weight of the parameter, or the default weight (dw) if the profile doesn't override it.
+// Returns false if the parameter is disabled.
+func (p *FaultProfile) GetWeight(name string, dw float64) (float64, bool) {
+	for _, disabled := range p.Disabled {
+		if disabled == name {
+			return 0, false
+		}
+	}
+
+	if weight, ok := p.Weights[name]; ok {
+		return weight, true
+	}
+	return dw, true
+}
+
+// Validate checks that the profile has no negative weights.
+// Parameter names are checked by the ParameterRegistry.
+func (p *FaultProfile) Validate() error {
+	for name, weight := range p.Weights {
+		if weight < 0 {
+			return fmt.Errorf("profile %s: weight of %s below 0 (%f)", p.Name, name, weight)
+		}
+	}
+	return nil
+}
+
+// LoadFaultProfiles reads a JSON array of fault profiles from the file (path).
+//
+// Returns an error if the file can't be read, any profile is invalid or names are duplicated.
+func LoadFaultProfiles(path string) ([]FaultProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read fault profiles: %s", err.Error())
+	}
+
+	var profiles []FaultProfile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("can't parse fault profiles: %s", err.Error())
+	}
+
+	names := map[string]bool{}
+	for i := range profiles {
+		if names[profiles[i].Name] {
+			return nil, fmt.Errorf("fault profile %s is duplicated", profiles[i].Name)
+		}
+		names[profiles[i].Name] = true
+
+		if err := profiles[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return profiles, nil
+}
+
+// LoadFaultProfile reads fault profiles from the file (path) and returns the one with the given name.
+func LoadFaultProfile(path, name string) (FaultProfile, error) {
+	profiles, err := LoadFaultProfiles(path)
+	if err != nil {
+		return FaultProfile{}, err
+	}
+
+	for _, profile := range profiles {
+		if profile.Name == name {
+			return profile, nil
+		}
+	}
+	return FaultProfile{}, fmt.Errorf("fault profile %s not found in %s", name, path)
+}
@@ -0,0 +1,236 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/Duckademic/schedule-generator/generator/entities"
+	"github.com/google/uuid"
+)
+
+// BoneWeek is the week-0 lesson placement. Each element is the slot of the study load with the same index.
+// A load without a slot has a day equal to -1.
+type BoneWeek []entities.LessonSlot
+
+// Clone returns an independent copy of the bone week.
+func (bw BoneWeek) Clone() BoneWeek {
+	return slices.Clone(bw)
+}
+
+// BoneWeekEvaluator rates a bone week. A lower value means a better bone week.
+type BoneWeekEvaluator func(BoneWeek) float64
+
+// CrossoverUnit defines which lessons are inherited together from one parent.
+type CrossoverUnit int
+
+const (
+	CrossoverByStudentGroup CrossoverUnit = iota // all lessons of a student group come from the same parent
+	CrossoverByTeacher                           // all lessons of a teacher come from the same parent
+)
+
+// GeneticConfig sets the population, operators and budgets of the genetic optimizer.
+type GeneticConfig struct {
+	Generations    int           // Generation budget. 0 disables the genetic optimizer.
+	PopulationSize int           // Number of bone weeks in each generation.
+	EliteCount     int           // Number of the best bone weeks copied to the next generation unchanged.
+	MutationRate   float64       // Probability for each lesson of a child to be moved to a random slot [0, 1].
+	Crossover      CrossoverUnit // Unit of inheritance.
+	TimeLimit      time.Duration // Time budget. 0 means no limit.
+}
+
+// IsEnabled returns true if the genetic optimizer has a generation budget.
+func (c *GeneticConfig) IsEnabled() bool {
+	return c.Generations > 0
+}
+
+// Validate checks the config of the enabled genetic optimizer. Returns an error if it is inconsistent.
+func (c *GeneticConfig) Validate() error {
+	if !c.IsEnabled() {
+		return nil
+	}
+	if c.PopulationSize < 2 {
+		return fmt.Errorf("population size below 2 (%d)", c.PopulationSize)
+	}
+	if c.EliteCount < 0 || c.EliteCount >= c.PopulationSize {
+		return fmt.Errorf("elite count %d outside of [0, %d)", c.EliteCount, c.PopulationSize)
+	}
+	if c.MutationRate < 0 || c.MutationRate > 1 {
+		return fmt.Errorf("mutation rate %f outside of [0, 1]", c.MutationRate)
+	}
+	if c.Crossover != CrossoverByStudentGroup && c.Crossover != CrossoverByTeacher {
+		return fmt.Errorf("unknown crossover unit %d", c.Crossover)
+	}
+	if c.TimeLimit < 0 {
+		return fmt.Errorf("time limit below 0 (%s)", c.TimeLimit)
+	}
+	return nil
+}
+
+// GeneticOptimizer searches for a better bone week with an evolutionary algorithm. Crossover inherits all lessons
+// of a student group or a teacher from one parent, mutation moves a lesson to a random slot of the week.
+type GeneticOptimizer interface {
+	OptimizerComponent      // Basic interface for optimizer component
+	Evolve(context.Context) // Runs evolution until any budget is exhausted or ctx is done
+	GetBest() BoneWeek      // Returns the best found bone week
+}
+
+// NewGeneticOptimizer creates a GeneticOptimizer instance.
+// It requires an ErrorService, genetic config, bone week study loads (l), the bone week that starts
+// the population (seed), a function that rates bone weeks and the seed of random decisions (randomSeed).
+func NewGeneticOptimizer(
+	es ErrorService, cfg GeneticConfig, l []*entities.UnassignedLesson, seed BoneWeek, evaluate BoneWeekEvaluator,
+	randomSeed int64,
+) GeneticOptimizer {
+	return &geneticOptimizer{
+		errorService: es,
+		cfg:          cfg,
+		loads:        l,
+		evaluate:     evaluate,
+		random:       rand.New(rand.NewSource(randomSeed)),
+		best:         individual{boneWeek: seed.Clone(), fitness: math.Inf(1)},
+		progress:     NewProgressReporter(nil),
+	}
+}
+
+type geneticOptimizer struct {
+	errorService ErrorService
+	cfg          GeneticConfig
+	loads        []*entities.UnassignedLesson
+	evaluate     BoneWeekEvaluator
+	random       *rand.Rand
+	best         individual
+	progress     ProgressReporter
+	iterations   int
+}
+
+type individual struct {
+	boneWeek BoneWeek
+	fitness  float64
+}
+
+func (ga *geneticOptimizer) Evolve(ctx context.Context) {
+	if len(ga.best.boneWeek) != len(ga.loads) {
+		ga.errorService.AddError(NewUnexpectedError("seed doesn't match study loads", "geneticOptimizer", "Evolve",
+			fmt.Errorf("seed length %d, loads count %d", len(ga.best.boneWeek), len(ga.loads))))
+		return
+	}
+
+	ga.progress.StartPhase(GeneticOptimizerPhase, ga.cfg.Generations)
+	defer func() { ga.progress.FinishPhase(ga.iterations) }()
+	started := time.Now()
+	population := make([]individual, ga.cfg.PopulationSize)
+	population[0] = ga.newIndividual(ga.best.boneWeek)
+	for i := 1; i < len(population); i++ {
+		population[i] = ga.newIndividual(ga.mutate(ga.best.boneWeek.Clone()))
+	}
+	ga.sort(population)
+
+	for ga.iterations = 0; ga.iterations < ga.cfg.Generations; ga.iterations++ {
+		if ga.cfg.TimeLimit > 0 && time.Since(started) > ga.cfg.TimeLimit || ctx.Err() != nil {
+			break
+		}
+
+		next := make([]individual, 0, len(population))
+		next = append(next, population[:ga.cfg.EliteCount]...)
+		for len(next) < len(population) {
+			child := ga.crossover(ga.selectParent(population), ga.selectParent(population))
+			next = append(next, ga.newIndividual(ga.mutate(child)))
+		}
+		population = next
+		ga.sort(population)
+		ga.progress.ProgressWithFault(ga.iterations, ga.best.fitness)
+	}
+}
+
+// sort orders the population by fitness and remembers the best individual.
+func (ga *geneticOptimizer) sort(population []individual) {
+	slices.SortStableFunc(population, func(a, b individual) int {
+		if a.fitness < b.fitness {
+			return -1
+		} else if a.fitness > b.fitness {
+			return 1
+		}
+		return 0
+	})
+	if population[0].fitness < ga.best.fitness {
+		ga.best = population[0]
+	}
+}
+
+func (ga *geneticOptimizer) newIndividual(bw BoneWeek) individual {
+	return individual{boneWeek: bw, fitness: ga.evaluate(bw)}
+}
+
+// selectParent returns the better of two random individuals (tournament selection).
+func (ga *geneticOptimizer) selectParent(population []individual) BoneWeek {
+	first := population[ga.random.Intn(len(population))]
+	second := population[ga.random.Intn(len(population))]
+	if second.fitness < first.fitness {
+		return second.boneWeek
+	}
+	return first.boneWeek
+}
+
+// crossover creates a child that inherits each student group or teacher from a random parent.
+func (ga *geneticOptimizer) crossover(first, second BoneWeek) BoneWeek {
+	fromFirst := map[uuid.UUID]bool{}
+	child := make(BoneWeek, len(first))
+	for i, load := range ga.loads {
+		unit := load.StudentGroup.ID
+		if ga.cfg.Crossover == CrossoverByTeacher {
+			unit = load.Teacher.ID
+		}
+
+		inherit, ok := fromFirst[unit]
+		if !ok {
+			inherit = ga.random.Intn(2) == 0
+			fromFirst[unit] = inherit
+		}
+
+		if inherit {
+			child[i] = first[i]
+		} else {
+			child[i] = second[i]
+		}
+	}
+	return child
+}
+
+// mutate moves lessons of the bone week to random slots with the MutationRate probability.
+func (ga *geneticOptimizer) mutate(bw BoneWeek) BoneWeek {
+	for i, load := range ga.loads {
+		if ga.random.Float64() < ga.cfg.MutationRate {
+			bw[i] = randomSlot(ga.random, &load.Teacher.BusyGrid)
+		}
+	}
+	return bw
+}
+
+// Redirect to Evolve function
+func (ga *geneticOptimizer) Run(ctx context.Context) {
+	ga.Evolve(ctx)
+}
+
+func (ga *geneticOptimizer) GetErrorService() ErrorService {
+	return ga.errorService
+}
+
+func (ga *geneticOptimizer) SetProgressReporter(pr ProgressReporter) {
+	ga.progress = pr
+}
+
+func (ga *geneticOptimizer) BestFault() float64 {
+	return ga.best.fitness
+}
+
+func (ga *geneticOptimizer) Iterations() int {
+	return ga.iterations
+}
+
+func (ga *geneticOptimizer) GetBest() BoneWeek {
+	return ga.best.boneWeek
+}
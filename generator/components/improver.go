@@ -1,14 +1,17 @@
 package components
 
 import (
+	"context"
+
 	"github.com/Duckademic/schedule-generator/generator/entities"
 	"github.com/Duckademic/schedule-generator/generator/services"
 )
 
 // Improver improves finished schedule
 type Improver interface {
-	ImproveToNext() bool // Improve improves schedule. Returns false if there are not available improvements
-	SubmitChanges()      // SubmitChanges submits changes after previous submit
+	// Improve improves schedule. Returns false if there are not available improvements or ctx is done.
+	ImproveToNext(ctx context.Context) bool
+	SubmitChanges() // SubmitChanges submits changes after previous submit
 }
 
 func NewImprover(lessonService services.LessonService) Improver {
@@ -23,10 +26,13 @@ type improver struct {
 }
 
 // looks for free slots to selected lessons. move lesson to it if found
-func (imp *improver) ImproveToNext() bool {
+func (imp *improver) ImproveToNext(ctx context.Context) bool {
 	lessons := imp.lessonService.GetAll()
 	// runs until finds free slot or be out of lessons
 	for {
+		if ctx.Err() != nil {
+			return false
+		}
 		imp.currentSlot.Slot += 1 // moves to the next slot instead of keeping the lesson in the same one
 		currentLesson := lessons[imp.currentLesson]
 		dayOutOfRange := false
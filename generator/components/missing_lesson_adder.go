@@ -1,6 +1,7 @@
 package components
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Duckademic/schedule-generator/generator/entities"
@@ -10,82 +11,84 @@ import (
 // MissingLessonsAdder adds missing lessons to the first available day
 // in both the teacher's and the student group's schedules.
 type MissingLessonsAdder interface {
-	GeneratorComponent  // Basic interface for generator component
-	AddMissingLessons() // Add a MissingLessonsAdderError to ErrorService
+	GeneratorComponent // Basic interface for generator component
+	// Add a MissingLessonsAdderError to ErrorService.
+	// Stops when ctx is done, the current and the remaining loads aren't checked.
+	AddMissingLessons(ctx context.Context)
 }
 
 // NewMissingLessonAdder creates a MissingLessonsAdder instance.
-// It requires an ErrorService, a list of teachers and a LessonService.
+// It requires an ErrorService, a list of study loads and a LessonService.
 func NewMissingLessonAdder(es ErrorService, l []*entities.UnassignedLesson, ls services.LessonService) MissingLessonsAdder {
-	return &missingLessonsAdder{errorService: es, loads: l, lessonService: ls}
+	return &missingLessonsAdder{errorService: es, loads: l, lessonService: ls, progress: NewProgressReporter(nil)}
 }
 
 type missingLessonsAdder struct {
-	errorService ErrorService
-	// teachers      []*entities.Teacher
+	errorService  ErrorService
 	loads         []*entities.UnassignedLesson
 	lessonService services.LessonService
+	progress      ProgressReporter
 }
 
-func (ma *missingLessonsAdder) AddMissingLessons() {
-	for _, load := range ma.loads {
+// AddMissingLessons walks through the days of the load's lesson type and places one lesson per day
+// in the optimal free slot until the teacher load is covered or the grid ends.
+func (ma *missingLessonsAdder) AddMissingLessons(ctx context.Context) {
+	ma.progress.StartPhase(MissingLessonsPhase, len(ma.loads))
+	defer ma.progress.FinishPhase(len(ma.loads))
+	for i, load := range ma.loads {
+		if ctx.Err() != nil {
+			return
+		}
+		ma.progress.Progress(i)
 		teacher := load.Teacher
 		studentGroup := load.StudentGroup
-		lessonType := load.Type
-		discipline := load.Discipline
-
-		currentDay := 0
-		outOfGrid := false
-		for !outOfGrid {
-			err := studentGroup.CheckDay(currentDay)
-			if err != nil {
-				outOfGrid = true
-				//continue
-				break
-			}
+		key := entities.NewTeacherLoadKey(load.Discipline, studentGroup, load.Type)
 
-			for i := range teacher.BusyGrid.Grid[currentDay] {
-				slot := entities.LessonSlot{
-					Day:  currentDay,
-					Slot: i,
-				}
-				ma.lessonService.AssignLesson(*load, slot)
-			}
-			delta := studentGroup.GetNextDayOfType(lessonType, currentDay+1)
-			if delta == -1 {
-				outOfGrid = true
-				continue
+		day := studentGroup.GetNextDayOfType(load.Type, 0)
+		for day != -1 && !teacher.IsEnoughLessonsFor(key) && ctx.Err() == nil {
+			slot := findOptimalSlot(ma.lessonService, load, day)
+			if slot != -1 {
+				// a failed assignment only means that this day doesn't fit, the next one is checked anyway
+				ma.lessonService.AssignLesson(*load, entities.NewLessonSlot(day, slot))
 			}
-			currentDay += delta
+			day = studentGroup.GetNextDayOfType(load.Type, day+1)
+		}
+		if ctx.Err() != nil {
+			return
 		}
 
-		if !teacher.IsEnoughLessonsFor(entities.NewTeacherLoadKey(discipline, studentGroup, lessonType)) {
+		if deficit := teacher.CountHourDeficitFor(key); deficit > 0 {
 			ma.errorService.AddError(&MissingLessonsAdderError{
 				UnassignedLesson: *load,
+				MissingHours:     deficit,
 			})
 		}
 	}
-
 }
 
 // Redirect to AddMissingLessons function
-func (ma *missingLessonsAdder) Run() {
-	ma.AddMissingLessons()
+func (ma *missingLessonsAdder) Run(ctx context.Context) {
+	ma.AddMissingLessons(ctx)
 }
 
 func (ma *missingLessonsAdder) GetErrorService() ErrorService {
 	return ma.errorService
 }
 
+func (ma *missingLessonsAdder) SetProgressReporter(pr ProgressReporter) {
+	ma.progress = pr
+}
+
 // MissingLessonsAdderError indicates that the MissingLessonsAdder failed to
 // find free slot in the grids for missing lesson.
 type MissingLessonsAdderError struct {
 	entities.UnassignedLesson
+	MissingHours int // Number of academic hours that are still not assigned for the load.
 }
 
 func (e *MissingLessonsAdderError) Error() string {
-	return fmt.Sprintf("Not enough space of %s or %s for %s %s.",
-		e.StudentGroup.Name, e.Teacher.UserName, e.Type.Name, e.Discipline.Name)
+	return fmt.Sprintf("Not enough space of %s or %s for %s %s: %d hours missing.",
+		e.StudentGroup.Name, e.Teacher.UserName, e.Type.Name, e.Discipline.Name, e.MissingHours)
 }
 
 func (e *MissingLessonsAdderError) GetTypeOfError() GeneratorComponentErrorTypes {
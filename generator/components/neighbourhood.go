@@ -0,0 +1,73 @@
+package components
+
+import (
+	"math/rand"
+
+	"github.com/Duckademic/schedule-generator/generator/entities"
+	"github.com/Duckademic/schedule-generator/generator/services"
+)
+
+// randomSlot returns a random slot within the grid. The slot can be busy or blocked,
+// so the caller must check it before use.
+func randomSlot(r *rand.Rand, grid *entities.BusyGrid) entities.LessonSlot {
+	day := r.Intn(len(grid.Grid))
+	if len(grid.Grid[day]) == 0 {
+		return entities.NewLessonSlot(day, 0)
+	}
+
+	return entities.NewLessonSlot(day, r.Intn(len(grid.Grid[day])))
+}
+
+// lessonSnapshot stores lesson positions to restore the best found schedule.
+// Lessons keep the order of the lesson service, so the restore is repeatable.
+type lessonSnapshot []storedLesson
+
+// storedLesson is a lesson with its stored slot.
+type storedLesson struct {
+	lesson *entities.Lesson
+	slot   entities.LessonSlot
+}
+
+func newLessonSnapshot(lessons []*entities.Lesson) lessonSnapshot {
+	snapshot := make(lessonSnapshot, len(lessons))
+	for i, lesson := range lessons {
+		snapshot[i] = storedLesson{lesson: lesson, slot: lesson.LessonSlot}
+	}
+	return snapshot
+}
+
+// restore moves lessons back to the stored slots. Lessons can block each other's way back,
+// so moves are repeated while at least one of them succeeds. Swapped lessons are swapped back.
+//
+// Returns the number of lessons that were not restored.
+func (s lessonSnapshot) restore(ls services.LessonService) (left int) {
+	for progress := true; progress; {
+		progress = false
+		left = 0
+		for _, stored := range s {
+			lesson, slot := stored.lesson, stored.slot
+			if lesson.LessonSlot == slot {
+				continue
+			}
+			if ls.MoveLessonTo(lesson, slot) == nil {
+				progress = true
+			} else if other := s.findDisplacedAt(slot); other != nil && ls.SwapLessons(lesson, other) == nil {
+				progress = true
+			} else {
+				left++
+			}
+		}
+	}
+	return
+}
+
+// findDisplacedAt returns the lesson that takes the slot, but is stored at another one.
+// Returns nil if there is no such lesson.
+func (s lessonSnapshot) findDisplacedAt(slot entities.LessonSlot) *entities.Lesson {
+	for _, stored := range s {
+		if stored.lesson.LessonSlot == slot && stored.slot != slot {
+			return stored.lesson
+		}
+	}
+	return nil
+}
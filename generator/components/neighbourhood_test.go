@@ -0,0 +1,106 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/Duckademic/schedule-generator/generator/entities"
+	"github.com/Duckademic/schedule-generator/generator/services"
+)
+
+// newSnapshotFixture assigns three lectures of one student group on Monday (slots 0-2) in a bone week
+// with lectures on Monday and Tuesday.
+func newSnapshotFixture(t *testing.T) (services.LessonService, *entities.StudentGroup) {
+	t.Helper()
+	grid := mondayGrid(1, 1, 1, 1)
+	grid[2] = []float32{1, 1, 1, 1}
+
+	f := newBoneWeekFixture()
+	sg := f.newStudentGroup(t, "group", grid)
+	if err := sg.BindWeekday(f.lecture, 2); err != nil {
+		t.Fatalf("BindWeekday() error = %v", err)
+	}
+	for _, name := range []string{"first", "second", "third"} {
+		f.addLoad(f.newTeacher(t, name, grid), sg)
+	}
+
+	ls, err := services.NewLessonService(2, nil, nil)
+	if err != nil {
+		t.Fatalf("NewLessonService() error = %v", err)
+	}
+	for i, load := range f.loads {
+		if err := ls.AssignLesson(*load, entities.NewLessonSlot(1, i)); err != nil {
+			t.Fatalf("AssignLesson() error = %v", err)
+		}
+	}
+	return ls, sg
+}
+
+func TestLessonSnapshotRestore(t *testing.T) {
+	tests := []struct {
+		name  string
+		apply func(t *testing.T, ls services.LessonService, lessons []*entities.Lesson)
+	}{
+		{
+			name: "moved lessons",
+			apply: func(t *testing.T, ls services.LessonService, lessons []*entities.Lesson) {
+				mustMove(t, ls, lessons[2], entities.NewLessonSlot(2, 0))
+				mustMove(t, ls, lessons[1], entities.NewLessonSlot(2, 1))
+			},
+		},
+		{
+			name: "swapped lessons",
+			apply: func(t *testing.T, ls services.LessonService, lessons []*entities.Lesson) {
+				if err := ls.SwapLessons(lessons[0], lessons[1]); err != nil {
+					t.Fatalf("SwapLessons() error = %v", err)
+				}
+			},
+		},
+		{
+			name: "lesson moved to the stored slot of another one",
+			apply: func(t *testing.T, ls services.LessonService, lessons []*entities.Lesson) {
+				mustMove(t, ls, lessons[2], entities.NewLessonSlot(2, 0))
+				mustMove(t, ls, lessons[0], entities.NewLessonSlot(1, 2))
+				if err := ls.SwapLessons(lessons[0], lessons[1]); err != nil {
+					t.Fatalf("SwapLessons() error = %v", err)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ls, sg := newSnapshotFixture(t)
+			lessons := ls.GetAll()
+			snapshot := newLessonSnapshot(lessons)
+			test.apply(t, ls, lessons)
+
+			if left := snapshot.restore(ls); left != 0 {
+				t.Fatalf("restore() left %d lessons", left)
+			}
+			for _, stored := range snapshot {
+				if stored.lesson.LessonSlot != stored.slot {
+					t.Errorf("lesson of %s is at %s, want %s",
+						stored.lesson.Teacher.UserName, stored.lesson.LessonSlot.String(), stored.slot.String())
+				}
+				if !stored.lesson.Teacher.IsLessonOn(stored.slot) {
+					t.Errorf("teacher %s grid has no lesson at %s", stored.lesson.Teacher.UserName, stored.slot.String())
+				}
+			}
+			for slot := range 4 {
+				if want := slot < 3; sg.IsLessonOn(entities.NewLessonSlot(1, slot)) != want {
+					t.Errorf("student group lesson at Monday slot %d = %t, want %t", slot, !want, want)
+				}
+				if sg.IsLessonOn(entities.NewLessonSlot(2, slot)) {
+					t.Errorf("student group has a lesson at Tuesday slot %d", slot)
+				}
+			}
+		})
+	}
+}
+
+func mustMove(t *testing.T, ls services.LessonService, lesson *entities.Lesson, slot entities.LessonSlot) {
+	t.Helper()
+	if err := ls.MoveLessonTo(lesson, slot); err != nil {
+		t.Fatalf("MoveLessonTo(%s) error = %v", slot.String(), err)
+	}
+}
@@ -0,0 +1,167 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/Duckademic/schedule-generator/generator/entities"
+	"github.com/google/uuid"
+)
+
+// FaultContext gives ScheduleParameter factories the generator data. The readers return live entities,
+// not copies, so read access is a contract the factories are trusted to keep: they must call
+// only the methods that don't change the entities (counters and getters, like the built-in parameters),
+// and must not keep the entities after the factory returns.
+type FaultContext struct {
+	Teachers      TeacherReader
+	StudentGroups StudentGroupReader
+	Lessons       LessonReader
+}
+
+// TeacherReader looks up the teachers for a ScheduleParameter factory.
+type TeacherReader interface {
+	Find(uuid.UUID) *entities.Teacher // Returns a pointer to the teacher with the given ID.
+	GetAll() []*entities.Teacher      // Returns a slice with all teachers as pointers.
+}
+
+// StudentGroupReader looks up the student groups for a ScheduleParameter factory.
+type StudentGroupReader interface {
+	Find(uuid.UUID) *entities.StudentGroup // Returns a pointer to the student group with the given ID.
+	GetAll() []*entities.StudentGroup      // Returns a slice with all student groups (subgroups too) as pointers.
+}
+
+// LessonReader lists the assigned lessons for a ScheduleParameter factory.
+type LessonReader interface {
+	GetAll() []*entities.Lesson // Returns a slice with all lessons as pointers.
+}
+
+// ScheduleParameterFactory creates a ScheduleParameter with the weight (w) for the current schedule state.
+type ScheduleParameterFactory func(ctx FaultContext, w float64) ScheduleParameter
+
+// ParameterRegistry stores named ScheduleParameter factories that form the ScheduleFault.
+type ParameterRegistry interface {
+	// Registers the factory with its default weight.
+	//
+	// Returns an error if the name is already taken or the weight is below zero.
+	Register(name string, weight float64, factory ScheduleParameterFactory) error
+	GetNames() []string                           // Returns names of the parameters in registration order.
+	GetDefaultWeight(name string) (float64, bool) // Returns the default weight, false if the name isn't registered.
+	CheckProfile(FaultProfile) error              // Returns an error if the profile is invalid or has unknown names.
+	// Creates a ScheduleFault with all parameters enabled by the profile.
+	Build(FaultContext, FaultProfile) ScheduleFault
+}
+
+// NewParameterRegistry creates an empty ParameterRegistry instance.
+func NewParameterRegistry() ParameterRegistry {
+	return &parameterRegistry{index: map[string]int{}}
+}
+
+// NewDefaultParameterRegistry creates a ParameterRegistry instance with the built-in parameters.
+func NewDefaultParameterRegistry() ParameterRegistry {
+	r := NewParameterRegistry()
+
+	builtIn := []struct {
+		name    string
+		weight  float64
+		factory ScheduleParameterFactory
+	}{
+		{TeacherWindowsParameter, 0.1, func(ctx FaultContext, w float64) ScheduleParameter {
+			return NewTeacherWindowsParameter(ctx.Teachers, w)
+		}},
+		{StudentGroupWindowsParameter, 1000, func(ctx FaultContext, w float64) ScheduleParameter {
+			return NewStudentGroupWindowsParameter(ctx.StudentGroups, w)
+		}},
+		{TeacherHoursDeficitParameter, 10, func(ctx FaultContext, w float64) ScheduleParameter {
+			return NewTeacherHoursDeficitParameter(ctx.Teachers, w)
+		}},
+		{StudentGroupHoursDeficitParameter, 10, func(ctx FaultContext, w float64) ScheduleParameter {
+			return NewStudentGroupHoursDeficitParameter(ctx.StudentGroups, w)
+		}},
+		{TeacherLessonOverlappingParameter, 10, func(ctx FaultContext, w float64) ScheduleParameter {
+			return NewTeacherLessonOverlappingParameter(ctx.Teachers, w)
+		}},
+		{StudentGroupLessonOverlappingParameter, 10, func(ctx FaultContext, w float64) ScheduleParameter {
+			return NewStudentGroupLessonOverlappingParameter(ctx.StudentGroups, w)
+		}},
+		{StudentGroupOvertimeLessonsParameter, 10, func(ctx FaultContext, w float64) ScheduleParameter {
+			return NewStudentGroupOvertimeLessonsParameter(ctx.StudentGroups, w)
+		}},
+		{StudentGroupInvalidLessonsByTypeParameter, 10, func(ctx FaultContext, w float64) ScheduleParameter {
+			return NewStudentGroupInvalidLessonsByTypeParameter(ctx.StudentGroups, w)
+		}},
+	}
+	for _, parameter := range builtIn {
+		if err := r.Register(parameter.name, parameter.weight, parameter.factory); err != nil {
+			panic(err)
+		}
+	}
+
+	return r
+}
+
+// parameterRegistry is the basic implementation of the ParameterRegistry interface.
+type parameterRegistry struct {
+	parameters []registeredParameter
+	index      map[string]int // name => position in parameters
+}
+
+type registeredParameter struct {
+	name    string
+	weight  float64
+	factory ScheduleParameterFactory
+}
+
+func (pr *parameterRegistry) Register(name string, weight float64, factory ScheduleParameterFactory) error {
+	if _, ok := pr.index[name]; ok {
+		return fmt.Errorf("parameter %s already registered", name)
+	}
+	if weight < 0 {
+		return fmt.Errorf("default weight of %s below 0 (%f)", name, weight)
+	}
+	if factory == nil {
+		return fmt.Errorf("factory of %s is nil", name)
+	}
+
+	pr.index[name] = len(pr.parameters)
+	pr.parameters = append(pr.parameters, registeredParameter{name: name, weight: weight, factory: factory})
+	return nil
+}
+func (pr *parameterRegistry) GetNames() []string {
+	names := make([]string, len(pr.parameters))
+	for i, parameter := range pr.parameters {
+		names[i] = parameter.name
+	}
+	return names
+}
+func (pr *parameterRegistry) GetDefaultWeight(name string) (float64, bool) {
+	i, ok := pr.index[name]
+	if !ok {
+		return 0, false
+	}
+	return pr.parameters[i].weight, true
+}
+func (pr *parameterRegistry) CheckProfile(profile FaultProfile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+
+	for name := range profile.Weights {
+		if _, ok := pr.index[name]; !ok {
+			return fmt.Errorf("profile %s: unknown parameter %s", profile.Name, name)
+		}
+	}
+	for _, name := range profile.Disabled {
+		if _, ok := pr.index[name]; !ok {
+			return fmt.Errorf("profile %s: unknown disabled parameter %s", profile.Name, name)
+		}
+	}
+	return nil
+}
+func (pr *parameterRegistry) Build(ctx FaultContext, profile FaultProfile) ScheduleFault {
+	result := NewScheduleFault()
+	for _, parameter := range pr.parameters {
+		if weight, ok := profile.GetWeight(parameter.name, parameter.weight); ok {
+			result.AddParameter(parameter.name, parameter.factory(ctx, weight))
+		}
+	}
+	return result
+}
@@ -0,0 +1,140 @@
+package components
+
+import (
+	"sync"
+	"time"
+)
+
+// ProgressEventType defines kinds of generation progress events.
+type ProgressEventType string
+
+const (
+	PhaseStartedEvent  ProgressEventType = "phase_started"
+	PhaseProgressEvent ProgressEventType = "progress"
+	PhaseFinishedEvent ProgressEventType = "phase_finished"
+	ErrorAddedEvent    ProgressEventType = "error"
+)
+
+// Names of the generation phases.
+const (
+	DayBlockerPhase         = "day_blocker"
+	BoneGeneratorPhase      = "bone_generator"
+	ExactBoneGeneratorPhase = "exact_bone_generator"
+	GeneticOptimizerPhase   = "genetic_optimizer"
+	CarcassPhase            = "carcass"
+	MissingLessonsPhase     = "missing_lessons_adder"
+	AnnealerPhase           = "annealer"
+	TabuSearchPhase         = "tabu_search"
+	MultiStartPhase         = "multi_start"
+)
+
+// ProgressEvent describes a step of the generation.
+type ProgressEvent struct {
+	Type  ProgressEventType `json:"type"`
+	Phase string            `json:"phase"`
+	Done  int               `json:"done"`            // placed loads, days or iterations of the phase
+	Total int               `json:"total"`           // 0 if the phase doesn't know its size
+	Fault *float64          `json:"fault,omitempty"` // current schedule fault, nil if the phase doesn't rate it
+	Error string            `json:"error,omitempty"` // added error of the ErrorAddedEvent
+	Time  time.Time         `json:"time"`
+}
+
+// ProgressListener receives progress events. It is called synchronously by the generator.
+type ProgressListener func(ProgressEvent)
+
+// ProgressReporter sends progress events of the current phase to the listener.
+// Progress events are thinned out to every percent of the phase total.
+type ProgressReporter interface {
+	SetListener(ProgressListener)              // Replaces the listener, nil disables events.
+	StartPhase(phase string, total int)        // Starts a new phase with the total number of steps.
+	Progress(done int)                         // Reports done steps of the current phase.
+	ProgressWithFault(done int, fault float64) // Reports done steps of the current phase and the current fault.
+	FinishPhase(done int)                      // Finishes the current phase.
+	ReportError(error)                         // Reports an error added during the current phase.
+}
+
+// NewProgressReporter creates a ProgressReporter instance. The listener (l) may be nil.
+func NewProgressReporter(l ProgressListener) ProgressReporter {
+	return &progressReporter{listener: l}
+}
+
+type progressReporter struct {
+	mutex       sync.Mutex
+	listener    ProgressListener
+	phase       string
+	total       int
+	lastPercent int
+}
+
+func (pr *progressReporter) SetListener(l ProgressListener) {
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
+
+	pr.listener = l
+}
+func (pr *progressReporter) StartPhase(phase string, total int) {
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
+
+	pr.phase, pr.total, pr.lastPercent = phase, total, -1
+	pr.send(ProgressEvent{Type: PhaseStartedEvent, Total: total})
+}
+func (pr *progressReporter) Progress(done int) {
+	pr.progress(done, nil)
+}
+func (pr *progressReporter) ProgressWithFault(done int, fault float64) {
+	pr.progress(done, &fault)
+}
+func (pr *progressReporter) FinishPhase(done int) {
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
+
+	pr.send(ProgressEvent{Type: PhaseFinishedEvent, Done: done})
+}
+func (pr *progressReporter) ReportError(err error) {
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
+
+	pr.send(ProgressEvent{Type: ErrorAddedEvent, Error: err.Error()})
+}
+
+func (pr *progressReporter) progress(done int, fault *float64) {
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
+
+	if pr.total > 0 {
+		percent := done * 100 / pr.total
+		if percent <= pr.lastPercent {
+			return
+		}
+		pr.lastPercent = percent
+	}
+	pr.send(ProgressEvent{Type: PhaseProgressEvent, Done: done, Fault: fault})
+}
+
+// send fills the phase data of the event and passes it to the listener. The mutex must be locked.
+func (pr *progressReporter) send(event ProgressEvent) {
+	if pr.listener == nil {
+		return
+	}
+	event.Phase = pr.phase
+	event.Total = pr.total
+	event.Time = time.Now()
+	pr.listener(event)
+}
+
+// NewReportingErrorService creates an ErrorService that reports every added error to the ProgressReporter (pr)
+// and stores it in the wrapped ErrorService (es).
+func NewReportingErrorService(es ErrorService, pr ProgressReporter) ErrorService {
+	return &reportingErrorService{ErrorService: es, progress: pr}
+}
+
+type reportingErrorService struct {
+	ErrorService
+	progress ProgressReporter
+}
+
+func (rs *reportingErrorService) AddError(err GeneratorComponentError) {
+	rs.ErrorService.AddError(err)
+	rs.progress.ReportError(err)
+}
@@ -2,7 +2,11 @@ package components
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+
+	"github.com/Duckademic/schedule-generator/generator/entities"
+	"github.com/google/uuid"
 )
 
 // ScheduleParameter defines a single component of the ScheduleFault.
@@ -10,6 +14,76 @@ import (
 type ScheduleParameter interface {
 	Fault() float64       // Calculates fault value based on the argument's internal calculation formula.
 	GetArguments() string // Returns formatted, human-readable representation of the arguments
+	Value() float64       // Returns the measured value of the parameter.
+	Weight() float64      // Returns the fault weight factor of the parameter.
+	// Returns the parts of the value caused by single teachers or student groups.
+	// Returns nil if the parameter isn't split by entities.
+	GetContributions() []FaultContribution
+}
+
+// EntityKind names the kind of the entity that contributes to a ScheduleParameter.
+type EntityKind string
+
+const (
+	TeacherEntity      EntityKind = "teacher"
+	StudentGroupEntity EntityKind = "student_group"
+)
+
+// FaultContribution is the part of a ScheduleParameter value caused by a single teacher or student group.
+type FaultContribution struct {
+	EntityKind EntityKind            `json:"entity_kind"` // Kind of the entity.
+	EntityID   uuid.UUID             `json:"entity_id"`   // Unique identifier of the entity.
+	EntityName string                `json:"entity_name"` // Human-readable identifier of the entity.
+	Value      float64               `json:"value"`       // Part of the parameter value.
+	Slots      []entities.LessonSlot `json:"slots"`       // Slots that cause the value (windows, lesson slots).
+	Lessons    []*entities.Lesson    `json:"-"`           // Lessons that cause the value.
+	LessonRefs []LessonRef           `json:"lessons"`     // Identifiers of the lessons that cause the value.
+}
+
+// LessonRef identifies a lesson that causes a fault. The ID is the ID of the exported lesson.
+type LessonRef struct {
+	ID              uuid.UUID           `json:"id"`
+	TeacherID       uuid.UUID           `json:"teacher_id"`
+	StudentGroupIDs uuid.UUIDs          `json:"student_group_ids"`
+	DisciplineID    uuid.UUID           `json:"discipline_id"`
+	LessonTypeID    uuid.UUID           `json:"lesson_type_id"`
+	Slot            entities.LessonSlot `json:"slot"`
+}
+
+// NewLessonRef creates a LessonRef of the lesson (l).
+func NewLessonRef(l *entities.Lesson) LessonRef {
+	ref := LessonRef{
+		ID:           l.ID,
+		TeacherID:    l.Teacher.ID,
+		DisciplineID: l.Discipline.ID,
+		LessonTypeID: l.Type.ID,
+		Slot:         l.LessonSlot,
+	}
+	for _, sg := range l.GetStudentGroups() {
+		ref.StudentGroupIDs = append(ref.StudentGroupIDs, sg.ID)
+	}
+	return ref
+}
+
+// NewTeacherContribution creates a FaultContribution of the teacher (t).
+// It requires the value (v) and offending lessons (l), their slots and identifiers are added to the contribution.
+func NewTeacherContribution(t *entities.Teacher, v float64, l []*entities.Lesson) FaultContribution {
+	return newContribution(TeacherEntity, t.ID, t.UserName, v, l)
+}
+
+// NewStudentGroupContribution creates a FaultContribution of the student group (sg).
+// It requires the value (v) and offending lessons (l), their slots and identifiers are added to the contribution.
+func NewStudentGroupContribution(sg *entities.StudentGroup, v float64, l []*entities.Lesson) FaultContribution {
+	return newContribution(StudentGroupEntity, sg.ID, sg.Name, v, l)
+}
+
+func newContribution(kind EntityKind, id uuid.UUID, name string, v float64, l []*entities.Lesson) FaultContribution {
+	fc := FaultContribution{EntityKind: kind, EntityID: id, EntityName: name, Value: v, Lessons: l}
+	for _, lesson := range l {
+		fc.Slots = append(fc.Slots, lesson.LessonSlot)
+		fc.LessonRefs = append(fc.LessonRefs, NewLessonRef(lesson))
+	}
+	return fc
 }
 
 // NewSimpleScheduleParameter a new simpleScheduleParameter instance.
@@ -30,12 +104,54 @@ func (ssp *simpleScheduleParameter) Fault() float64 {
 func (ssp *simpleScheduleParameter) GetArguments() string {
 	return fmt.Sprintf("value: %f, fault %f", ssp.value, ssp.fault)
 }
+func (ssp *simpleScheduleParameter) Value() float64 {
+	return ssp.value
+}
+func (ssp *simpleScheduleParameter) Weight() float64 {
+	return ssp.fault
+}
+func (ssp *simpleScheduleParameter) GetContributions() []FaultContribution {
+	return nil
+}
+
+// NewEntityScheduleParameter creates a ScheduleParameter split by entities.
+// The value is the sum of contribution (c) values, it is multiplied by the fault weight factor (f).
+// Contributions with zero value are dropped.
+func NewEntityScheduleParameter(c []FaultContribution, f float64) ScheduleParameter {
+	esp := entityScheduleParameter{simpleScheduleParameter: simpleScheduleParameter{fault: f}}
+	for _, contribution := range c {
+		if contribution.Value != 0 {
+			esp.value += contribution.Value
+			esp.contributions = append(esp.contributions, contribution)
+		}
+	}
+	return &esp
+}
+
+type entityScheduleParameter struct {
+	simpleScheduleParameter
+	contributions []FaultContribution
+}
+
+func (esp *entityScheduleParameter) GetContributions() []FaultContribution {
+	return esp.contributions
+}
+
+// ParameterBreakdown is the structured representation of a single ScheduleParameter.
+type ParameterBreakdown struct {
+	Name          string              `json:"name"`          // Name of the parameter.
+	Value         float64             `json:"value"`         // Measured value.
+	Weight        float64             `json:"weight"`        // Fault weight factor.
+	Fault         float64             `json:"fault"`         // Value multiplied by weight.
+	Contributions []FaultContribution `json:"contributions"` // Parts of the value caused by single entities.
+}
 
 // ScheduleFault represents fault for generated schedule.
 type ScheduleFault interface {
 	AddParameter(name string, parameter ScheduleParameter) // Adds new ScheduleParameter or replace it with a new one.
 	Fault() float64                                        // Returns sum of the ScheduleParameter Faults.
 	GetParameters() string                                 // Returns formatted, human-readable representation of the parameters.
+	GetBreakdown() []ParameterBreakdown                    // Returns parameters as structured data ordered by name.
 }
 
 // NewScheduleFault creates a new ScheduleFault instance.
@@ -45,14 +161,20 @@ func NewScheduleFault() ScheduleFault {
 
 type scheduleFault struct {
 	parameters map[string]ScheduleParameter
+	names      []string // names of parameters in the order they were added
 }
 
 func (sf *scheduleFault) AddParameter(name string, parameter ScheduleParameter) {
+	if _, ok := sf.parameters[name]; !ok {
+		sf.names = append(sf.names, name)
+	}
 	sf.parameters[name] = parameter
 }
+
+// Fault sums the parameters in the order they were added, so the float sum is the same on every call.
 func (sf *scheduleFault) Fault() (res float64) {
-	for _, fault := range sf.parameters {
-		res += fault.Fault()
+	for _, name := range sf.names {
+		res += sf.parameters[name].Fault()
 	}
 	return
 }
@@ -62,11 +184,31 @@ func (sf *scheduleFault) GetParameters() string {
 	}
 
 	var b strings.Builder
-	for key, value := range sf.parameters {
-		if value.Fault() != 0 {
+	for _, key := range sf.names {
+		if value := sf.parameters[key]; value.Fault() != 0 {
 			b.WriteString(fmt.Sprintf("%s: %s, fault: %f \n", key, value.GetArguments(), value.Fault()))
 		}
 	}
 
 	return b.String()
 }
+func (sf *scheduleFault) GetBreakdown() []ParameterBreakdown {
+	result := make([]ParameterBreakdown, 0, len(sf.parameters))
+	for name, parameter := range sf.parameters {
+		result = append(result, ParameterBreakdown{
+			Name:          name,
+			Value:         parameter.Value(),
+			Weight:        parameter.Weight(),
+			Fault:         parameter.Fault(),
+			Contributions: parameter.GetContributions(),
+		})
+	}
+
+	slices.SortFunc(result, func(a, b ParameterBreakdown) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
+
+// FaultEvaluator rates the current state of the schedule. Optimizers use it as the objective function.
+type FaultEvaluator func() ScheduleFault
@@ -0,0 +1,229 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/Duckademic/schedule-generator/generator/entities"
+	"github.com/Duckademic/schedule-generator/generator/services"
+)
+
+// TabuConfig sets the neighbourhood, tabu tenure and budgets of the tabu search.
+type TabuConfig struct {
+	MaxIterations     int           // Iteration budget. 0 disables tabu search.
+	NeighbourhoodSize int           // Number of random moves and swaps rated every iteration.
+	Tenure            int           // Number of iterations a moved lesson and its left slot stay tabu.
+	TimeLimit         time.Duration // Time budget. 0 means no limit.
+}
+
+// IsEnabled returns true if tabu search has an iteration budget.
+func (c *TabuConfig) IsEnabled() bool {
+	return c.MaxIterations > 0
+}
+
+// Validate checks the config of the enabled tabu search. Returns an error if it is inconsistent.
+func (c *TabuConfig) Validate() error {
+	if !c.IsEnabled() {
+		return nil
+	}
+	if c.NeighbourhoodSize <= 0 {
+		return fmt.Errorf("neighbourhood size below/equal to 0 (%d)", c.NeighbourhoodSize)
+	}
+	if c.Tenure < 0 {
+		return fmt.Errorf("tenure below 0 (%d)", c.Tenure)
+	}
+	if c.TimeLimit < 0 {
+		return fmt.Errorf("time limit below 0 (%s)", c.TimeLimit)
+	}
+	return nil
+}
+
+// TabuSearcher improves a finished schedule with tabu search. The neighbourhood consists of random lesson
+// moves and swaps, recently moved lessons and their left slots are tabu unless the move gives
+// a new best ScheduleFault (aspiration criterion).
+type TabuSearcher interface {
+	OptimizerComponent      // Basic interface for optimizer component
+	Search(context.Context) // Runs the search until any budget is exhausted or ctx is done, leaves the best found schedule
+}
+
+// NewTabuSearcher creates a TabuSearcher instance.
+// It requires an ErrorService, tabu config, a LessonService, a function that rates the schedule
+// and the seed of random decisions.
+func NewTabuSearcher(
+	es ErrorService, cfg TabuConfig, ls services.LessonService, fault FaultEvaluator, seed int64,
+) TabuSearcher {
+	return &tabuSearcher{
+		errorService:  es,
+		cfg:           cfg,
+		lessonService: ls,
+		fault:         fault,
+		random:        rand.New(rand.NewSource(seed)),
+		lessonTabu:    map[*entities.Lesson]int{},
+		slotTabu:      map[tabuSlot]int{},
+		progress:      NewProgressReporter(nil),
+	}
+}
+
+type tabuSearcher struct {
+	errorService  ErrorService
+	cfg           TabuConfig
+	lessonService services.LessonService
+	fault         FaultEvaluator
+	random        *rand.Rand
+	lessonTabu    map[*entities.Lesson]int // lesson => first iteration when it isn't tabu
+	slotTabu      map[tabuSlot]int         // lesson and left slot => first iteration when returning isn't tabu
+	progress      ProgressReporter
+	bestFault     float64
+	iterations    int
+}
+
+// tabuSlot is the slot left by the lesson.
+type tabuSlot struct {
+	lesson *entities.Lesson
+	slot   entities.LessonSlot
+}
+
+// tabuMove is a neighbourhood step: the lesson moves to the slot (to), or swaps with other lesson if it is set.
+type tabuMove struct {
+	lesson *entities.Lesson
+	to     entities.LessonSlot
+	other  *entities.Lesson
+}
+
+func (m *tabuMove) apply(ls services.LessonService) error {
+	if m.other != nil {
+		return ls.SwapLessons(m.lesson, m.other)
+	}
+	return ls.MoveLessonTo(m.lesson, m.to)
+}
+
+// undo returns the lessons to the slots they had before apply (from).
+func (m *tabuMove) undo(ls services.LessonService, from entities.LessonSlot) error {
+	if m.other != nil {
+		return ls.SwapLessons(m.lesson, m.other)
+	}
+	return ls.MoveLessonTo(m.lesson, from)
+}
+
+func (ts *tabuSearcher) Search(ctx context.Context) {
+	lessons := ts.lessonService.GetAll()
+	ts.bestFault = ts.fault().Fault()
+	ts.progress.StartPhase(TabuSearchPhase, ts.cfg.MaxIterations)
+	defer func() { ts.progress.FinishPhase(ts.iterations) }()
+	if len(lessons) == 0 {
+		return
+	}
+
+	best := newLessonSnapshot(lessons)
+	current := ts.bestFault
+	started := time.Now()
+	for ts.iterations = 0; ts.iterations < ts.cfg.MaxIterations; ts.iterations++ {
+		if ts.cfg.TimeLimit > 0 && time.Since(started) > ts.cfg.TimeLimit || ctx.Err() != nil {
+			break
+		}
+
+		ts.progress.ProgressWithFault(ts.iterations, current)
+		move, fault, found := ts.selectMove(lessons)
+		if !found {
+			continue
+		}
+
+		from := move.lesson.LessonSlot
+		if move.apply(ts.lessonService) != nil {
+			continue
+		}
+		ts.makeTabu(move.lesson, from)
+		if move.other != nil {
+			ts.makeTabu(move.other, move.lesson.LessonSlot)
+		}
+
+		current = fault
+		if current < ts.bestFault {
+			ts.bestFault = current
+			best = newLessonSnapshot(lessons)
+		}
+	}
+
+	if current > ts.bestFault && best.restore(ts.lessonService) != 0 {
+		ts.bestFault = ts.fault().Fault()
+	}
+}
+
+// selectMove rates random neighbours and returns the best admissible one with its fault.
+// Returns false if no neighbour can be applied.
+func (ts *tabuSearcher) selectMove(lessons []*entities.Lesson) (best tabuMove, bestFault float64, found bool) {
+	for range ts.cfg.NeighbourhoodSize {
+		move := ts.randomMove(lessons)
+		from := move.lesson.LessonSlot
+		if move.apply(ts.lessonService) != nil {
+			continue
+		}
+		fault := ts.fault().Fault()
+		if err := move.undo(ts.lessonService, from); err != nil {
+			ts.errorService.AddError(NewUnexpectedError("applied move can't be undone",
+				"tabuSearcher", "selectMove", err))
+			return move, fault, false
+		}
+
+		// aspiration criterion: a tabu move is allowed if it gives the new best schedule
+		if ts.isTabu(move) && fault >= ts.bestFault {
+			continue
+		}
+		if !found || fault < bestFault {
+			best, bestFault, found = move, fault, true
+		}
+	}
+	return
+}
+
+func (ts *tabuSearcher) randomMove(lessons []*entities.Lesson) tabuMove {
+	move := tabuMove{lesson: lessons[ts.random.Intn(len(lessons))]}
+	if ts.random.Intn(2) == 0 {
+		move.to = randomSlot(ts.random, &move.lesson.Teacher.BusyGrid)
+		return move
+	}
+
+	move.other = lessons[ts.random.Intn(len(lessons))]
+	move.to = move.other.LessonSlot
+	return move
+}
+
+func (ts *tabuSearcher) isTabu(move tabuMove) bool {
+	if ts.lessonTabu[move.lesson] > ts.iterations || ts.slotTabu[tabuSlot{move.lesson, move.to}] > ts.iterations {
+		return true
+	}
+	if move.other == nil {
+		return false
+	}
+	return ts.lessonTabu[move.other] > ts.iterations ||
+		ts.slotTabu[tabuSlot{move.other, move.lesson.LessonSlot}] > ts.iterations
+}
+
+// makeTabu forbids moving the lesson and returning it to the left slot (from) during the tenure.
+func (ts *tabuSearcher) makeTabu(lesson *entities.Lesson, from entities.LessonSlot) {
+	ts.lessonTabu[lesson] = ts.iterations + ts.cfg.Tenure + 1
+	ts.slotTabu[tabuSlot{lesson, from}] = ts.iterations + ts.cfg.Tenure + 1
+}
+
+// Redirect to Search function
+func (ts *tabuSearcher) Run(ctx context.Context) {
+	ts.Search(ctx)
+}
+
+func (ts *tabuSearcher) GetErrorService() ErrorService {
+	return ts.errorService
+}
+
+func (ts *tabuSearcher) SetProgressReporter(pr ProgressReporter) {
+	ts.progress = pr
+}
+
+func (ts *tabuSearcher) BestFault() float64 {
+	return ts.bestFault
+}
+
+func (ts *tabuSearcher) Iterations() int {
+	return ts.iterations
+}
@@ -2,14 +2,15 @@ package entities
 
 import (
 	"fmt"
+	"slices"
 )
 
 // NewBusyGrid creates new BusyGrid instance.
 //
-// It requires a grid with coefficients of comfort (grid).
+// It requires a grid with coefficients of comfort (grid) and weekdays of its days (cal, nil for the bone cycle).
 // Function copies the array before creating a new instance.
-func NewBusyGrid(grid [][]float32) *BusyGrid {
-	bg := BusyGrid{Grid: make([][]float32, len(grid))}
+func NewBusyGrid(grid [][]float32, cal *Calendar) *BusyGrid {
+	bg := BusyGrid{Grid: make([][]float32, len(grid)), Calendar: cal}
 	for i := range grid {
 		bg.Grid[i] = make([]float32, len(grid[i]))
 		copy(bg.Grid[i], grid[i])
@@ -19,7 +20,8 @@ func NewBusyGrid(grid [][]float32) *BusyGrid {
 
 // BusyGrid represents the grid of business for other entities.
 type BusyGrid struct {
-	Grid [][]float32 // positive - slot is free, negative - slot is busy by lesson, 0 - slot is busy for other reasons
+	Grid     [][]float32 // positive - slot is free, negative - slot is busy by lesson, 0 - slot is busy for other reasons
+	Calendar *Calendar   // weekdays and dates of the grid days
 }
 
 // GetFreeSlot returns optimal slot index of the day.
@@ -112,8 +114,8 @@ func (bg *BusyGrid) BlockWeekDay(day int) error {
 		return err
 	}
 
-	for week := 0; bg.CheckDay(day+week*7) == nil; week++ {
-		err := bg.BlockFullDay(day + week*7)
+	for _, currentDay := range bg.GetDaysOfWeekday(day) {
+		err := bg.BlockFullDay(currentDay)
 		if err != nil {
 			panic(err)
 		}
@@ -122,6 +124,45 @@ func (bg *BusyGrid) BlockWeekDay(day int) error {
 	return nil
 }
 
+// LimitWeekDay marks all slots of the specified weekday as blocked, except the available ones (slots).
+//
+// Returns an error if day is not weekday or any available slot is outside of every day of the weekday.
+func (bg *BusyGrid) LimitWeekDay(day int, slots []int) error {
+	if err := bg.CheckWeekDay(day); err != nil {
+		return err
+	}
+	days := bg.GetDaysOfWeekday(day)
+	slotCount := 0
+	for _, currentDay := range days {
+		slotCount = max(slotCount, len(bg.Grid[currentDay]))
+	}
+	for _, slot := range slots {
+		if slot < 0 || len(days) != 0 && slot >= slotCount {
+			return SlotOutError{min: 0, max: slotCount, input: slot, day: day}
+		}
+	}
+
+	for _, currentDay := range days {
+		for slot := range bg.Grid[currentDay] {
+			if !slices.Contains(slots, slot) {
+				bg.BlockSlot(NewLessonSlot(currentDay, slot))
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetDaysOfWeekday returns the grid days that follow the weekday.
+func (bg *BusyGrid) GetDaysOfWeekday(weekday int) (days []int) {
+	for day := range bg.Grid {
+		if bg.Calendar.GetWeekday(day) == weekday {
+			days = append(days, day)
+		}
+	}
+	return
+}
+
 // BlockFullDay marks all slots of the day as blocked.
 //
 // Returns an error if dai isn't within the grid.
@@ -297,15 +338,20 @@ func (bg *BusyGrid) IsBlocked(slot LessonSlot) bool {
 // ==========================================================================================================
 
 // CountWindows returns the sum of windows (gaps between busy slots).
-func (bg *BusyGrid) CountWindows() (count int) {
+func (bg *BusyGrid) CountWindows() int {
+	return len(bg.GetWindows())
+}
+
+// GetWindows returns free slots that are placed between busy slots of the same day.
+func (bg *BusyGrid) GetWindows() (windows []LessonSlot) {
 	// Days cycle
 	for day := range len(bg.Grid) {
 		lastBusy := -1
 		// Slots cycle
 		for slot := range bg.Grid[day] {
 			if !bg.IsFree(NewLessonSlot(day, slot)) {
-				if lastBusy != -1 && (slot-lastBusy) > 1 {
-					count += slot - lastBusy - 1
+				for window := lastBusy + 1; lastBusy != -1 && window < slot; window++ {
+					windows = append(windows, NewLessonSlot(day, window))
 				}
 				lastBusy = slot
 			}
@@ -337,8 +383,7 @@ func (bg *BusyGrid) CountLessonsOn(day int) (count int) {
 func (bg *BusyGrid) GetWeekDaysPriority() (result []float32) {
 	result = make([]float32, 7)
 	for day := range 7 {
-		for week := 0; bg.CheckDay(day+week*7) == nil; week++ {
-			currentDay := day + week*7
+		for week, currentDay := range bg.GetDaysOfWeekday(day) {
 			var average float32 = 0
 			for slot, value := range bg.Grid[currentDay] {
 				average = ((average * float32(slot)) + value) / (float32(slot) + 1)
@@ -358,8 +403,7 @@ func (bg *BusyGrid) CountSlotsOnDay(day int) (count int) {
 		return
 	}
 
-	for week := 0; bg.CheckDay(day+week*7) == nil; week++ {
-		currentDay := day + week*7
+	for _, currentDay := range bg.GetDaysOfWeekday(day) {
 		for slot := range bg.Grid[currentDay] {
 			if bg.IsFree(LessonSlot{Day: currentDay, Slot: slot}) {
 				count++
@@ -370,21 +414,21 @@ func (bg *BusyGrid) CountSlotsOnDay(day int) (count int) {
 }
 
 // CountLessonOverlapping returns the count of overlapping lessons. Counts only lessons that overlap.
-func (bg *BusyGrid) CountLessonOverlapping(lessons []*Lesson) (count int) {
-	for _, lesson := range lessons {
-		// if lesson in not busy slot => overlap or other error
-		if !bg.IsLessonOn(lesson.LessonSlot) {
-			count++
-		}
-
-		// sets slot as free so the next lesson with the same slot wouldn't pass the check
-		bg.SetSlotBusyState(lesson.LessonSlot, false)
-	}
+func (bg *BusyGrid) CountLessonOverlapping(lessons []*Lesson) int {
+	return len(bg.GetOverlappingLessons(lessons))
+}
 
-	// return grid to it first state
+// GetOverlappingLessons returns lessons that overlap with previous ones (or are at not busy slots).
+// The grid isn't changed.
+func (bg *BusyGrid) GetOverlappingLessons(lessons []*Lesson) (result []*Lesson) {
+	taken := map[LessonSlot]bool{}
 	for _, lesson := range lessons {
-		bg.SetSlotBusyState(lesson.LessonSlot, true)
+		// a lesson at a not busy slot or at the slot of a previous lesson => overlap or other error
+		if !bg.IsLessonOn(lesson.LessonSlot) || taken[lesson.LessonSlot] {
+			result = append(result, lesson)
+		}
+		taken[lesson.LessonSlot] = true
 	}
 
-	return count
+	return result
 }
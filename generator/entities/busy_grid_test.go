@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGetOverlappingLessonsKeepsGrid(t *testing.T) {
+	bg := NewBusyGrid([][]float32{{1, 1, 1}}, nil)
+	taken, free := NewLessonSlot(0, 0), NewLessonSlot(0, 2)
+	bg.SetSlotBusyState(taken, true)
+	grid := slices.Clone(bg.Grid[0])
+
+	// the second lesson at the taken slot overlaps the first one, the lesson at the free slot isn't on the grid
+	lessons := []*Lesson{
+		NewLesson(uuid.New(), UnassignedLesson{}, taken, 2),
+		NewLesson(uuid.New(), UnassignedLesson{}, taken, 2),
+		NewLesson(uuid.New(), UnassignedLesson{}, free, 2),
+	}
+	got := bg.GetOverlappingLessons(lessons)
+	if !slices.Equal(got, lessons[1:]) {
+		t.Errorf("GetOverlappingLessons() = %d lessons, want the second and the third", len(got))
+	}
+	if !slices.Equal(bg.Grid[0], grid) {
+		t.Errorf("grid = %v after GetOverlappingLessons(), want %v", bg.Grid[0], grid)
+	}
+}
@@ -0,0 +1,75 @@
+package entities
+
+import "time"
+
+// Calendar maps days of a grid to weekdays and dates. A day follows the timetable, the lesson type bindings
+// and the blocked-day rules of its weekday, so a transferred working day takes the weekday it works as.
+//
+// Weeks start on Sunday, so the first week of a grid that starts on another weekday is shorter.
+// A nil Calendar describes a grid without dates (the bone cycle), where the weekday of a day is its index modulo 7.
+type Calendar struct {
+	start    time.Time // Date of the first grid day.
+	weekdays []int     // Weekday of every grid day, 0 - Sunday.
+}
+
+// NewCalendar creates a new Calendar instance.
+//
+// It requires the date of the first grid day (start) and the weekday of every grid day (weekdays, 0 - Sunday).
+func NewCalendar(start time.Time, weekdays []int) *Calendar {
+	return &Calendar{start: start, weekdays: weekdays}
+}
+
+// GetWeekday returns the weekday that the day follows. Returns -1 if the day is outside the calendar.
+func (c *Calendar) GetWeekday(day int) int {
+	if day < 0 {
+		return -1
+	}
+	if c == nil {
+		return day % 7
+	}
+	if day >= len(c.weekdays) {
+		return -1
+	}
+	return c.weekdays[day]
+}
+
+// GetWeek returns the number of the week the day belongs to.
+func (c *Calendar) GetWeek(day int) int {
+	if !c.HasDates() {
+		return day / 7
+	}
+	// weeks follow the real dates, a transferred day stays in its week
+	return (day + int(c.start.Weekday())) / 7
+}
+
+// CountWeeks returns the number of weeks the grid days belong to, including a short first and last week.
+func (c *Calendar) CountWeeks(days int) int {
+	if days <= 0 {
+		return 0
+	}
+	return c.GetWeek(days-1) + 1
+}
+
+// HasDates returns true if grid days have dates.
+func (c *Calendar) HasDates() bool {
+	return c != nil && !c.start.IsZero()
+}
+
+// GetDate returns the date of the day. Returns zero time if the calendar has no dates.
+func (c *Calendar) GetDate(day int) time.Time {
+	if !c.HasDates() {
+		return time.Time{}
+	}
+	return c.start.AddDate(0, 0, day)
+}
+
+// GetDay returns the index of the date in the grid (negative if the date is before the first day).
+// Returns -1 if the calendar has no dates.
+func (c *Calendar) GetDay(date time.Time) int {
+	if !c.HasDates() {
+		return -1
+	}
+	from := time.Date(c.start.Year(), c.start.Month(), c.start.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
@@ -28,3 +28,31 @@ type DayOutError struct {
 func (d DayOutError) Error() string {
 	return fmt.Sprintf("day %d outside of BusyGrid (%d to %d)", d.input, d.min, d.max)
 }
+
+// LessonRule names a rule of the lesson checks of teachers and student groups.
+type LessonRule string
+
+const (
+	SlotRule    LessonRule = "slot"     // the slot is outside of the grid
+	BusyRule    LessonRule = "busy"     // the slot is taken or unavailable
+	HoursRule   LessonRule = "hours"    // the load has enough hours
+	DayLoadRule LessonRule = "day_load" // the day is fully loaded
+	WindowRule  LessonRule = "window"   // the lesson creates a window
+	DayTypeRule LessonRule = "day_type" // the day has another lesson type
+)
+
+// LessonCheckError is returned by CheckLesson of teachers and student groups with the broken rule.
+//
+// Error: the error of the check
+type LessonCheckError struct {
+	Rule LessonRule
+	err  error
+}
+
+func (e LessonCheckError) Error() string {
+	return e.err.Error()
+}
+
+func (e LessonCheckError) Unwrap() error {
+	return e.err
+}
@@ -2,21 +2,27 @@ package entities
 
 import (
 	"fmt"
+
+	"github.com/google/uuid"
 )
 
 // Lesson represents an assigned lesson based on an UnsignedLesson.
 type Lesson struct {
-	UnassignedLesson     // Base lesson data without time assignment.
-	LessonSlot           // Assigned time slot
-	Value            int // Number of academic hours
+	ID               uuid.UUID // Unique identifier of the Lesson.
+	UnassignedLesson           // Base lesson data without time assignment.
+	LessonSlot                 // Assigned time slot
+	Value            int       // Number of academic hours
+	Room             *Room     // Assigned room, nil if the generator works without rooms.
+	Pinned           bool      // The lesson keeps its slot, it can't be moved or swapped.
 }
 
 // NewLesson creates a new Lesson instance.
 //
-// It requires an unassigned lesson definition (ul),
+// It requires lesson id, an unassigned lesson definition (ul),
 // an assigned lesson slot (ls), and lesson value in academic hours (v).
-func NewLesson(ul UnassignedLesson, ls LessonSlot, v int) *Lesson {
+func NewLesson(id uuid.UUID, ul UnassignedLesson, ls LessonSlot, v int) *Lesson {
 	return &Lesson{
+		ID:               id,
 		UnassignedLesson: ul,
 		LessonSlot:       ls,
 		Value:            v,
@@ -71,8 +77,9 @@ func (ls *LessonSlot) After(other LessonSlot) bool {
 type UnassignedLesson struct {
 	Type         *LessonType   // Type of the lesson.
 	Teacher      *Teacher      // Assigned teacher.
-	StudentGroup *StudentGroup // Assigned group.
+	StudentGroup *StudentGroup // Assigned group, the main one if the lesson is attended by a stream.
 	Discipline   *Discipline   // Subject for the lesson.
+	Stream       *Stream       // Groups attending the lesson together, nil if the lesson has only one group.
 }
 
 // NewUnassignedLesson creates a new UnsignedLesson instance.
@@ -88,6 +95,50 @@ func NewUnassignedLesson(lt *LessonType, t *Teacher, sg *StudentGroup, d *Discip
 	}
 }
 
+// GetStudentGroups returns all groups attending the lesson, the main group first.
+func (ul *UnassignedLesson) GetStudentGroups() []*StudentGroup {
+	if ul.Stream == nil {
+		return []*StudentGroup{ul.StudentGroup}
+	}
+	return ul.Stream.StudentGroups
+}
+
+// CountStudents returns the number of students attending the lesson.
+func (ul *UnassignedLesson) CountStudents() (count int) {
+	for _, sg := range ul.GetStudentGroups() {
+		count += sg.Size
+	}
+	return
+}
+
+// GetFreeSlots returns free slots of the selected day (day) that are available for all groups of the lesson.
+// Comfort coefficients are taken from the main group. Other groups of the stream make the whole day unavailable
+// if the day is not of the lesson type or they are fully loaded.
+//
+// If a day out of the grid returns an empty array.
+func (ul *UnassignedLesson) GetFreeSlots(day int) []float32 {
+	slots := ul.StudentGroup.GetFreeSlots(day)
+	if ul.Stream == nil {
+		return slots
+	}
+
+	for _, sg := range ul.Stream.StudentGroups {
+		if sg == ul.StudentGroup {
+			continue
+		}
+		if !sg.IsDayOfType(ul.Type, day) || sg.CheckDayOverload(day) {
+			return make([]float32, len(slots))
+		}
+		other := sg.GetFreeSlots(day)
+		for i := range slots {
+			if i >= len(other) || other[i] <= 0 {
+				slots[i] = 0
+			}
+		}
+	}
+	return slots
+}
+
 // Validate checks whether all required fields of UnassignedLesson are set.
 // It returns an error describing the first missing field.
 func (ul *UnassignedLesson) Validate() error {
@@ -103,6 +154,21 @@ func (ul *UnassignedLesson) Validate() error {
 	if ul.Discipline == nil {
 		return fmt.Errorf("discipline is not assigned")
 	}
+	if ul.Stream != nil && (len(ul.Stream.StudentGroups) == 0 || ul.Stream.StudentGroups[0] != ul.StudentGroup) {
+		return fmt.Errorf("stream doesn't start with the student group")
+	}
 
 	return nil
 }
+
+// Stream represents student groups that attend the lessons of a joint load together (e.g. a stream lecture).
+type Stream struct {
+	StudentGroups []*StudentGroup // Groups of the stream, the first one is the main group of the lessons.
+}
+
+// NewStream creates a new Stream instance.
+//
+// It requires student groups of the stream (sgs), the first one is the main group.
+func NewStream(sgs []*StudentGroup) *Stream {
+	return &Stream{StudentGroups: sgs}
+}
@@ -12,6 +12,7 @@ type LessonType struct {
 	Name        string    // Human-readable identifier of the LessonType.
 	Weeks       []int     // List of week numbers when only this type can be assigned.
 	Value       int       // Number of academic hours assigned to this LessonType
+	RoomType    string    // Type of room required for lessons of this type, any room if empty.
 	DayRequired int       // <-- NOT RESPONSIBILITY OF THIS OBJECT
 }
 
@@ -34,10 +35,13 @@ type LessonTypeBinder interface {
 }
 
 // NewLessonTypeBinder creates a new basic LessonTypeChecker instance.
-func NewLessonTypeBinder() LessonTypeBinder {
+//
+// It requires weekdays of the grid days (cal, nil for the bone cycle).
+func NewLessonTypeBinder(cal *Calendar) LessonTypeBinder {
 	return &lessonTypeBinder{
 		weekBinding: make(map[int]*LessonType),
 		dayBinding:  make([]*LessonType, 7),
+		calendar:    cal,
 	}
 }
 
@@ -45,6 +49,7 @@ func NewLessonTypeBinder() LessonTypeBinder {
 type lessonTypeBinder struct {
 	weekBinding map[int]*LessonType
 	dayBinding  []*LessonType
+	calendar    *Calendar
 }
 
 func (c *lessonTypeBinder) BindWeek(lt *LessonType, week int) error {
@@ -70,12 +75,16 @@ func (c *lessonTypeBinder) BindWeekday(lt *LessonType, day int) error {
 	return nil
 }
 func (c *lessonTypeBinder) IsDayOfType(lt *LessonType, day int) bool {
-	trueLT, ok := c.weekBinding[day/7]
+	trueLT, ok := c.weekBinding[c.calendar.GetWeek(day)]
 	if ok {
 		return trueLT == lt
 	}
 
-	return c.dayBinding[day%7] == lt
+	weekday := c.calendar.GetWeekday(day)
+	if weekday == -1 {
+		return false
+	}
+	return c.dayBinding[weekday] == lt
 }
 func (c *lessonTypeBinder) IsWeekday(day int) bool {
 	return day >= 0 && day <= 6
@@ -3,6 +3,7 @@ package entities
 import (
 	"fmt"
 	"os"
+	"time"
 )
 
 type PersonalSchedule struct {
@@ -30,9 +31,18 @@ func (ps *PersonalSchedule) WritePS(lessonToString func(*Lesson) string) error {
 	}
 	defer file.Close()
 
+	dayNames := []string{"Неділя", "Понеділок", "Вівторок", "Середа", "Четвер", "П'ятниця", "Субота"}
 	lessonIndex := 0
 	for day := range ps.BusyGrid.Grid {
-		dayStr := []string{"Неділя", "Понеділок", "Вівторок", "Середа", "Четвер", "П'ятниця", "Субота"}[day%7]
+		weekday := ps.BusyGrid.Calendar.GetWeekday(day)
+		dayStr := dayNames[weekday]
+		if ps.BusyGrid.Calendar.HasDates() {
+			date := ps.BusyGrid.Calendar.GetDate(day)
+			dayStr = fmt.Sprintf("%s %s", dayNames[date.Weekday()], date.Format(time.DateOnly))
+			if int(date.Weekday()) != weekday {
+				dayStr += fmt.Sprintf(" (за розкладом: %s)", dayNames[weekday])
+			}
+		}
 		_, err := file.WriteString(fmt.Sprintf("%s (день %d) \n", dayStr, day))
 		if err != nil {
 			return err
@@ -0,0 +1,33 @@
+package entities
+
+import "github.com/google/uuid"
+
+// Room represents a classroom in the scheduling context.
+//
+// The model disallows simultaneous lessons and checks the room type and capacity.
+type Room struct {
+	BusyGrid           // Availability grid.
+	ID       uuid.UUID // Unique identifier of the Room.
+	Name     string    // Human-readable identifier of the Room.
+	Capacity int       // Number of seats.
+	Type     string    // Type of the room (lecture hall, computer lab, etc.).
+}
+
+// NewRoom creates a new Room instance.
+//
+// It requires room's id, name, capacity (c), room type (rt) and busy grid for the room (bg).
+func NewRoom(id uuid.UUID, name string, c int, rt string, bg *BusyGrid) *Room {
+	return &Room{
+		BusyGrid: *bg,
+		ID:       id,
+		Name:     name,
+		Capacity: c,
+		Type:     rt,
+	}
+}
+
+// Fits returns true if the room has the required room type (rt) and enough seats for students (s).
+// An empty room type matches any room, zero students match any capacity.
+func (r *Room) Fits(rt string, s int) bool {
+	return (rt == "" || r.Type == rt) && r.Capacity >= s
+}
@@ -11,13 +11,16 @@ import (
 //
 // The model enforces curriculum load constraints, disallows simultaneous classes and day overloads.
 type StudentGroup struct {
-	BusyGrid                     // Availability grid.
-	StudentLoadService           // Handles student group load validation logic.
-	LessonTypeBinder             // Handles lesson type binding logic.
-	ID                 uuid.UUID // Unique identifier of the StudentGroup.
-	Name               string    // Human-readable identifier of the StudentGroup.
-	MaxLessonsPerDay   int       // Day load limit.
+	BusyGrid                         // Availability grid.
+	StudentLoadService               // Handles student group load validation logic.
+	LessonTypeBinder                 // Handles lesson type binding logic.
+	ID                 uuid.UUID     // Unique identifier of the StudentGroup.
+	Name               string        // Human-readable identifier of the StudentGroup.
+	MaxLessonsPerDay   int           // Day load limit.
+	Size               int           // Number of students, 0 if unknown.
+	Parent             *StudentGroup // Group the subgroup belongs to, nil for a whole group.
 	connectedGroups    []*StudentGroup
+	subgroups          []*StudentGroup
 }
 
 // NewStudentGroup creates a new StudentGroup instance.
@@ -41,7 +44,32 @@ func NewStudentGroup(
 //
 // It requires student group id, name, max lessons per day (dl), and busy grid (bg).
 func NewDefaultStudentGroup(id uuid.UUID, name string, dl int, bg *BusyGrid) *StudentGroup {
-	return NewStudentGroup(id, name, dl, bg, NewStudentLoadService(), NewLessonTypeBinder())
+	return NewStudentGroup(id, name, dl, bg, NewStudentLoadService(), NewLessonTypeBinder(bg.Calendar))
+}
+
+// NewSubgroup creates a new StudentGroup instance as a part of the parent group (p).
+//
+// The subgroup copies the parent's busy grid and shares its lesson type binding. It requires subgroup id and name.
+func NewSubgroup(id uuid.UUID, name string, p *StudentGroup) *StudentGroup {
+	sub := NewStudentGroup(id, name, p.MaxLessonsPerDay, NewBusyGrid(p.Grid, p.Calendar), NewStudentLoadService(), p.LessonTypeBinder)
+	sub.Parent = p
+	p.subgroups = append(p.subgroups, sub)
+
+	return sub
+}
+
+// ==========================================================================================================
+// ================================================ Subgroups ===============================================
+// ==========================================================================================================
+
+// GetSubgroups returns subgroups of the student group.
+func (sg *StudentGroup) GetSubgroups() []*StudentGroup {
+	return sg.subgroups
+}
+
+// IsSubgroup returns true if the student group is a part of another group.
+func (sg *StudentGroup) IsSubgroup() bool {
+	return sg.Parent != nil
 }
 
 // ==========================================================================================================
@@ -89,6 +117,7 @@ func (sg *StudentGroup) CountConnectedGroupsNumber() int {
 // }
 
 // GetFreeSlots returns free slots of the selected day (day).
+// A group with subgroups returns the slots that are free for all of them.
 //
 // If a day out of the grid returns an empty array.
 func (sg *StudentGroup) GetFreeSlots(day int) (slots []float32) {
@@ -96,6 +125,19 @@ func (sg *StudentGroup) GetFreeSlots(day int) (slots []float32) {
 		return []float32{}
 	}
 
+	if len(sg.subgroups) != 0 {
+		slots = sg.subgroups[0].GetFreeSlots(day)
+		for _, sub := range sg.subgroups[1:] {
+			other := sub.GetFreeSlots(day)
+			for i := range slots {
+				if other[i] <= 0 {
+					slots[i] = 0
+				}
+			}
+		}
+		return
+	}
+
 	slots = make([]float32, len(sg.Grid[day]))
 
 	// the group hasn't lesson that day
@@ -127,26 +169,37 @@ func (sg *StudentGroup) GetFreeSlots(day int) (slots []float32) {
 	return
 }
 
-// MoveLessonTo uses the MoveLessonTo BusyGrid method to move the lesson. If the lesson can't be moved
-// returns the error generated by the LessonCanBeMoved method.
+// MoveLessonTo uses the MoveLessonTo BusyGrid method to move the lesson in the group and subgroups grids.
+// If the lesson can't be moved returns the error generated by the LessonCanBeMoved method.
 func (sg *StudentGroup) MoveLessonTo(lesson *Lesson, to LessonSlot) error {
 	if err := sg.LessonCanBeMoved(lesson, to); err != nil {
 		return err
 	}
 
+	for _, sub := range sg.subgroups {
+		sub.BusyGrid.MoveLessonTo(lesson.LessonSlot, to)
+	}
 	return sg.BusyGrid.MoveLessonTo(lesson.LessonSlot, to)
 }
 
-// LessonCanBeMoved uses the LessonCanBeMoved BusyGrid check on the first order, then additionally
-// checks the type of the day.
+// LessonCanBeMoved uses the LessonCanBeMoved BusyGrid check for the group and subgroups on the first order,
+// then additionally checks the type and the load of the day.
 func (sg *StudentGroup) LessonCanBeMoved(lesson *Lesson, to LessonSlot) error {
 	if err := sg.BusyGrid.LessonCanBeMoved(lesson.LessonSlot, to); err != nil {
 		return err
 	}
+	for _, sub := range sg.subgroups {
+		if err := sub.BusyGrid.LessonCanBeMoved(lesson.LessonSlot, to); err != nil {
+			return fmt.Errorf("subgroup %s: %s", sub.Name, err.Error())
+		}
+	}
 
 	if !sg.IsDayOfType(lesson.Type, to.Day) {
 		return fmt.Errorf("%d is not day of the type %s", to.Day, lesson.Type.Name)
 	}
+	if to.Day != lesson.Day && sg.CheckDayOverload(to.Day) {
+		return fmt.Errorf("student group %s is fully loaded at day %d", sg.Name, to.Day)
+	}
 
 	return nil
 }
@@ -213,15 +266,91 @@ func (sg *StudentGroup) BindWeekday(lType *LessonType, day int) error {
 // ==========================================================================================================
 
 // CheckDayOverload returns false if the student group has fewer lessons than the limit, set as MaxLessonsPerDay.
-// It uses the CountLessonsOn method to get the number of lessons.
+// It uses the CountLessonsOn method to get the number of lessons. A group with subgroups is overloaded
+// if any subgroup is, because subgroups have both their own and whole group lessons.
 func (sg *StudentGroup) CheckDayOverload(day int) bool {
 	if err := sg.CheckDay(day); err != nil {
 		return true
 	}
 
+	for _, sub := range sg.subgroups {
+		if sub.CheckDayOverload(day) {
+			return true
+		}
+	}
 	return sg.CountLessonsOn(day) >= sg.MaxLessonsPerDay
 }
 
+// IsFree checks if the slot is free for the group and all its subgroups.
+// If an error occurs, returns false.
+func (sg *StudentGroup) IsFree(slot LessonSlot) bool {
+	for _, sub := range sg.subgroups {
+		if !sub.IsFree(slot) {
+			return false
+		}
+	}
+	return sg.BusyGrid.IsFree(slot)
+}
+
+// SetSlotBusyState marks the slot as busy or free in the group and subgroups grids.
+func (sg *StudentGroup) SetSlotBusyState(slot LessonSlot, isBusy bool) error {
+	for _, sub := range sg.subgroups {
+		if err := sub.SetSlotBusyState(slot, isBusy); err != nil {
+			return err
+		}
+	}
+	return sg.BusyGrid.SetSlotBusyState(slot, isBusy)
+}
+
+// CheckGapOnAdd checks if the slot is free and adding the lesson does not create a gap.
+// A group with subgroups is checked with their grids, they contain lessons of the whole group too.
+// Returns an error if it is not.
+func (sg *StudentGroup) CheckGapOnAdd(slot LessonSlot) error {
+	if len(sg.subgroups) == 0 {
+		return sg.BusyGrid.CheckGapOnAdd(slot)
+	}
+
+	if !sg.BusyGrid.IsFree(slot) {
+		return fmt.Errorf("slot (%s) not free", slot.String())
+	}
+	for _, sub := range sg.subgroups {
+		if err := sub.CheckGapOnAdd(slot); err != nil {
+			return fmt.Errorf("subgroup %s: %s", sub.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// CountWindows returns the number of windows (gaps between busy slots).
+func (sg *StudentGroup) CountWindows() int {
+	return len(sg.GetWindows())
+}
+
+// GetWindows returns free slots between busy slots. A group with subgroups has a window
+// at the slot where at least one subgroup has it, even if the other one has a lesson.
+func (sg *StudentGroup) GetWindows() (windows []LessonSlot) {
+	if len(sg.subgroups) == 0 {
+		return sg.BusyGrid.GetWindows()
+	}
+
+	for _, sub := range sg.subgroups {
+		for _, window := range sub.GetWindows() {
+			if !slices.Contains(windows, window) {
+				windows = append(windows, window)
+			}
+		}
+	}
+	slices.SortFunc(windows, func(a, b LessonSlot) int {
+		if a.After(b) {
+			return 1
+		} else if b.After(a) {
+			return -1
+		}
+		return 0
+	})
+	return
+}
+
 // AddLesson registers the lesson at all dependent services.
 //
 // Returns an error if CheckLesson fails.
@@ -244,50 +373,74 @@ func (sg *StudentGroup) AddLesson(lesson *Lesson) error {
 // CheckLesson checks if the lesson can be added. It checks slot validation, availability,
 // day load, curriculum limits and possible formation of the gap.
 //
-// Return an error if validation fails.
+// Return a LessonCheckError if validation fails.
 func (sg *StudentGroup) CheckLesson(lesson *Lesson) error {
 	if err := sg.CheckSlot(lesson.LessonSlot); err != nil {
-		return err
+		return LessonCheckError{Rule: SlotRule, err: err}
 	}
 	if !sg.IsFree(lesson.LessonSlot) {
-		return fmt.Errorf("student group is busy")
+		return LessonCheckError{Rule: BusyRule, err: fmt.Errorf("student group is busy")}
 	}
 	if sg.CheckDayOverload(lesson.Day) {
-		return fmt.Errorf("student group is fully loaded for this day")
+		return LessonCheckError{Rule: DayLoadRule, err: fmt.Errorf("student group is fully loaded for this day")}
 	}
 	if err := sg.CheckGapOnAdd(lesson.LessonSlot); err != nil {
-		return err
+		return LessonCheckError{Rule: WindowRule, err: err}
 	}
 
 	if sg.IsEnoughLessons() {
-		return fmt.Errorf("student group is fully loaded")
+		return LessonCheckError{Rule: HoursRule, err: fmt.Errorf("student group is fully loaded")}
 	}
 
 	if !sg.IsDayOfType(lesson.Type, lesson.Day) {
-		return fmt.Errorf("type %s not in the correct day", lesson.Type.Name)
+		return LessonCheckError{Rule: DayTypeRule, err: fmt.Errorf("type %s not in the correct day", lesson.Type.Name)}
 	}
 
 	return nil
 }
 
 // CountOvertimeLessons returns the total number of overtime lessons (above the daily limit) for the student group.
-func (sg *StudentGroup) CountOvertimeLessons() (result int) {
-	for day := 0; true; day++ {
-		count := sg.CountLessonsOn(day)
-		if count == -1 {
-			break
+func (sg *StudentGroup) CountOvertimeLessons() int {
+	return len(sg.GetOvertimeLessons())
+}
+
+// GetOvertimeLessons returns lessons above the daily limit. Lessons of the overloaded day are taken
+// in slot order, so the latest ones are returned. A subgroup counts the whole group lessons into the day load,
+// they take its grid as well, but returns only its own lessons, the whole group returns the rest.
+func (sg *StudentGroup) GetOvertimeLessons() (result []*Lesson) {
+	days := map[int][]*Lesson{}
+	for group := sg; group != nil; group = group.Parent {
+		for _, lesson := range group.GetAssignedLessons() {
+			days[lesson.Day] = append(days[lesson.Day], lesson)
 		}
-		result += max(0, count-sg.MaxLessonsPerDay)
+	}
+
+	for day := 0; sg.CheckDay(day) == nil; day++ {
+		overtime := len(days[day]) - sg.MaxLessonsPerDay
+		if overtime <= 0 {
+			continue
+		}
+		lessons := slices.DeleteFunc(days[day], func(lesson *Lesson) bool {
+			return !slices.Contains(lesson.GetStudentGroups(), sg)
+		})
+		slices.SortStableFunc(lessons, func(a, b *Lesson) int {
+			return a.Slot - b.Slot
+		})
+		result = append(result, lessons[max(len(lessons)-overtime, 0):]...)
 	}
 	return
 }
 
 // CountInvalidLessonsType returns the total number of lesson scheduled on days that are not allowed for their type.
-func (sg *StudentGroup) CountInvalidLessonsByType() (result int) {
-	lessons := sg.GetAssignedLessons()
-	for _, lesson := range lessons {
+func (sg *StudentGroup) CountInvalidLessonsByType() int {
+	return len(sg.GetInvalidLessonsByType())
+}
+
+// GetInvalidLessonsByType returns lessons scheduled on days that are not allowed for their type.
+func (sg *StudentGroup) GetInvalidLessonsByType() (result []*Lesson) {
+	for _, lesson := range sg.GetAssignedLessons() {
 		if !sg.IsDayOfType(lesson.Type, lesson.Day) {
-			result += 1
+			result = append(result, lesson)
 		}
 	}
 
@@ -317,6 +470,7 @@ func NewStudentLoadService() StudentLoadService {
 // studentLoadService is the basic implementation of the StudentLoadService interface.
 type studentLoadService struct {
 	loads map[StudentLoadKey]studentLoad
+	keys  []StudentLoadKey // keys of loads in the order they were added, loads are iterated in it
 }
 
 func (s *studentLoadService) AddLesson(lesson *Lesson) {
@@ -335,22 +489,22 @@ func (s *studentLoadService) AddLesson(lesson *Lesson) {
 	panic("student load not found")
 }
 func (s *studentLoadService) CountHourDeficit() (count int) {
-	for _, load := range s.loads {
-		count += load.checker.CountHourDeficit()
+	for _, key := range s.keys {
+		count += s.loads[key].checker.CountHourDeficit()
 	}
 	return
 }
 func (s *studentLoadService) IsEnoughLessons() bool {
-	for _, load := range s.loads {
-		if !load.checker.IsEnoughLessons() {
+	for _, key := range s.keys {
+		if !s.loads[key].checker.IsEnoughLessons() {
 			return false
 		}
 	}
 	return true
 }
 func (s *studentLoadService) GetAssignedLessons() (result []*Lesson) {
-	for _, load := range s.loads {
-		result = append(result, load.checker.GetAssignedLessons()...)
+	for _, key := range s.keys {
+		result = append(result, s.loads[key].checker.GetAssignedLessons()...)
 	}
 	return
 }
@@ -359,10 +513,11 @@ func (s *studentLoadService) AddLoad(key StudentLoadKey, hours int) {
 		s.loads[key] = studentLoad{
 			checker: NewLoadService(hours),
 		}
+		s.keys = append(s.keys, key)
 	}
 }
 func (s *studentLoadService) GetOwnLessonTypes() (result []*LessonType) {
-	for key := range s.loads {
+	for _, key := range s.keys {
 		if !slices.Contains(result, key.lessonType) {
 			result = append(result, key.lessonType)
 		}
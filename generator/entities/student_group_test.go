@@ -0,0 +1,60 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGetOvertimeLessonsOfSubgroups(t *testing.T) {
+	grid := make([][]float32, 7)
+	grid[1] = []float32{1, 1, 1, 1}
+	lecture := &LessonType{ID: uuid.New(), Name: "lecture", Value: 2}
+	discipline := NewDiscipline(uuid.New(), "discipline")
+
+	parent := NewDefaultStudentGroup(uuid.New(), "group", 2, NewBusyGrid(grid, nil))
+	first := NewSubgroup(uuid.New(), "first", parent)
+	second := NewSubgroup(uuid.New(), "second", parent)
+
+	// lessons are registered without checks, as overridden edits are
+	addLesson := func(sg *StudentGroup, slot int) *Lesson {
+		teacher := NewDefaultTeacher(uuid.New(), "teacher", 0, NewBusyGrid(grid, nil))
+		sg.AddLoad(NewStudentLoadKey(discipline, lecture, teacher), 2)
+		lesson := NewLesson(uuid.New(), *NewUnassignedLesson(lecture, teacher, sg, discipline), NewLessonSlot(1, slot), 2)
+		sg.StudentLoadService.AddLesson(lesson)
+		return lesson
+	}
+	// the whole group has three lessons on Monday, the first subgroup has one more before them
+	firstOwn := addLesson(first, 0)
+	addLesson(parent, 1)
+	addLesson(parent, 2)
+	parentLast := addLesson(parent, 3)
+
+	tests := []struct {
+		name string
+		sg   *StudentGroup
+		want []*Lesson
+	}{
+		{name: "whole group", sg: parent, want: []*Lesson{parentLast}},
+		{name: "subgroup with its own lesson", sg: first, want: []*Lesson{firstOwn}},
+		{name: "subgroup without own lessons", sg: second},
+	}
+
+	count := 0
+	for _, test := range tests {
+		got := test.sg.GetOvertimeLessons()
+		if len(got) != len(test.want) {
+			t.Errorf("%s: GetOvertimeLessons() = %d lessons, want %d", test.name, len(got), len(test.want))
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%s: overtime lesson %d at %s, want %s", test.name, i, got[i].LessonSlot.String(), test.want[i].LessonSlot.String())
+			}
+		}
+		count += test.sg.CountOvertimeLessons()
+	}
+	if count != 2 {
+		t.Errorf("overtime lessons of the group and its subgroups = %d, want 2", count)
+	}
+}
@@ -9,9 +9,7 @@ import (
 // Teacher represents a university teacher in the scheduling context.
 //
 // The model enforces teaching load constraints for groups and disallows
-// simultaneous classes.
-//
-// TODO: add teacher availability constraints.
+// simultaneous classes. Availability constraints are stored in the BusyGrid as blocked slots.
 type Teacher struct {
 	BusyGrid                     // Availability grid.
 	TeacherLoadService           // Handles teacher load validation logic.
@@ -57,16 +55,16 @@ func (t *Teacher) AddLesson(lesson *Lesson) error {
 
 // CheckLesson checks if the lesson can be added. It checks slot validation, availability and load limits.
 //
-// Return an error if validation fails.
+// Return a LessonCheckError if validation fails.
 func (t *Teacher) CheckLesson(lesson *Lesson) error {
 	if err := t.CheckSlot(lesson.LessonSlot); err != nil {
-		return err
+		return LessonCheckError{Rule: SlotRule, err: err}
 	}
 	if !t.IsFree(lesson.LessonSlot) {
-		return fmt.Errorf("teacher is busy")
+		return LessonCheckError{Rule: BusyRule, err: fmt.Errorf("teacher is busy")}
 	}
 	if t.IsEnoughLessons() {
-		return fmt.Errorf("teacher %s has enough hours", t.UserName)
+		return LessonCheckError{Rule: HoursRule, err: fmt.Errorf("teacher %s has enough hours", t.UserName)}
 	}
 
 	return nil
@@ -78,6 +76,7 @@ type TeacherLoadService interface {
 	AddLoad(key TeacherLoadKey, hours int) // Registers a new required load entry.
 	// Returns true if the teacher doesn't require additional lessons for the specific load.
 	IsEnoughLessonsFor(TeacherLoadKey) bool
+	CountHourDeficitFor(TeacherLoadKey) int // Returns the number of missing study hours for the specific load.
 }
 
 // NewTeacherLoadService creates a new TeacherLoadService basic instance.
@@ -90,6 +89,7 @@ func NewTeacherLoadService() TeacherLoadService {
 // teacherLoadService is the basic implementation of the TeacherLoadService interface.
 type teacherLoadService struct {
 	loads map[TeacherLoadKey]teacherLoad
+	keys  []TeacherLoadKey // keys of loads in the order they were added, loads are iterated in it
 }
 
 func (s *teacherLoadService) AddLesson(lesson *Lesson) {
@@ -107,15 +107,15 @@ func (s *teacherLoadService) AddLesson(lesson *Lesson) {
 	}
 }
 func (s *teacherLoadService) CountHourDeficit() (count int) {
-	for _, load := range s.loads {
-		count += load.checker.CountHourDeficit()
+	for _, key := range s.keys {
+		count += s.loads[key].checker.CountHourDeficit()
 	}
 
 	return
 }
 func (s *teacherLoadService) IsEnoughLessons() bool {
-	for _, load := range s.loads {
-		if !load.checker.IsEnoughLessons() {
+	for _, key := range s.keys {
+		if !s.loads[key].checker.IsEnoughLessons() {
 			return false
 		}
 	}
@@ -123,8 +123,8 @@ func (s *teacherLoadService) IsEnoughLessons() bool {
 	return true
 }
 func (s *teacherLoadService) GetAssignedLessons() (result []*Lesson) {
-	for _, load := range s.loads {
-		result = append(result, load.checker.GetAssignedLessons()...)
+	for _, key := range s.keys {
+		result = append(result, s.loads[key].checker.GetAssignedLessons()...)
 	}
 
 	return
@@ -133,6 +133,7 @@ func (s *teacherLoadService) AddLoad(key TeacherLoadKey, hours int) {
 	_, ok := s.loads[key]
 	if !ok {
 		s.loads[key] = teacherLoad{checker: NewLoadService(hours)}
+		s.keys = append(s.keys, key)
 	}
 }
 func (s *teacherLoadService) IsEnoughLessonsFor(key TeacherLoadKey) bool {
@@ -143,6 +144,14 @@ func (s *teacherLoadService) IsEnoughLessonsFor(key TeacherLoadKey) bool {
 
 	return load.checker.IsEnoughLessons()
 }
+func (s *teacherLoadService) CountHourDeficitFor(key TeacherLoadKey) int {
+	load, ok := s.loads[key]
+	if !ok {
+		return 0
+	}
+
+	return load.checker.CountHourDeficit()
+}
 
 // NewTeacherLoadKey creates a new TeacherLoadKey instance.
 //
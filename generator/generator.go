@@ -1,32 +1,65 @@
 package generator
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Duckademic/schedule-generator/generator/components"
 	"github.com/Duckademic/schedule-generator/generator/entities"
 	"github.com/Duckademic/schedule-generator/generator/services"
 	"github.com/Duckademic/schedule-generator/types"
+	"github.com/google/uuid"
 )
 
 type ScheduleGeneratorConfig struct {
 	LessonsValue       int
 	Start              time.Time
 	End                time.Time
-	WorkLessons        [][]float32 // ПОЧАТОК З НЕДІЛІ нд пн вт ср чт пт сб, зберігає коефіцієнти зручності
-	MaxStudentWorkload int         // максимальна кількість пар для студентів на день
-	FillPercentage     float64     // відсоток заповненості типом пар для визначення кількості днів
+	WorkLessons        [][]float32                  // ПОЧАТОК З НЕДІЛІ нд пн вт ср чт пт сб, зберігає коефіцієнти зручності
+	MaxStudentWorkload int                          // максимальна кількість пар для студентів на день
+	FillPercentage     float64                      // відсоток заповненості типом пар для визначення кількості днів
+	Annealing          components.AnnealingConfig   // simulated annealing phase, disabled with zero MaxIterations
+	Tabu               components.TabuConfig        // tabu search phase (runs after annealing), disabled with zero MaxIterations
+	Genetic            components.GeneticConfig     // bone week evolution, disabled with zero Generations
+	ExactBoneWeek      components.ExactBoneConfig   // exact bone week solver instead of the greedy one
+	MultiStart         MultiStartConfig             // independent concurrent generations, the lowest fault one is kept
+	Seed               int64                        // seed of random decisions, the same input and seed give the same schedule without time limits
+	BoneCycleWeeks     int                          // weeks in the bone cycle (2 for odd/even weeks), 1 if zero
+	Calendar           CalendarConfig               // holidays, shortened and transferred days of the semester
+	Bells              BellSchedule                 // clock times of the slots, lessons have no times if empty
+	FaultProfile       components.FaultProfile      // weights of the ScheduleFault parameters, default if empty
+	FaultParameters    components.ParameterRegistry `json:"-"` // ScheduleFault parameters, built-in ones if nil
+}
+
+// generatorInput keeps the database models the generator was set with, so independent instances can be rebuilt.
+type generatorInput struct {
+	teachers      []types.Teacher
+	studentGroups []types.StudentGroup
+	disciplines   []types.Discipline
+	lessonTypes   []types.LessonType
+	studyLoads    []types.StudyLoad
+	rooms         []types.Room
+	pinnedLessons []types.GeneratedLesson
+	lessons       []types.Lesson // lessons of the schedule edited by hand
 }
 
 type generatorData struct {
 	busyGrid            [][]float32
+	calendar            *entities.Calendar // weekdays and dates of the grid days, nil for the bone cycle
 	teacherService      services.TeacherService
 	studentGroupService services.StudentGroupService
 	lessonService       services.LessonService
 	disciplineService   services.DisciplineService
 	lessonTypeService   services.LessonTypeService
 	studyLoadService    services.StudyLoadService
+	roomService         services.RoomService // nil if lessons are assigned without rooms
 }
 
 // 0 - teacher, 1 - student group, 2 - discipline, 3 - lesson type service.
@@ -55,8 +88,17 @@ func (g *generatorData) CheckServices(services []bool) error {
 type ScheduleGenerator struct {
 	ScheduleGeneratorConfig
 	generatorData
+	input        generatorInput
 	errorService components.ErrorService
 	weekData     generatorData
+	boneLoads    []*entities.UnassignedLesson // study loads of the bone cycle, a load is repeated for every its lesson
+	optimizers   []components.OptimizerComponent
+	shortfall    []CalendarShortfall
+	progress     components.ProgressReporter
+	random       *rand.Rand     // seeds the random decisions of the phases
+	ids          *rand.Rand     // source of lesson and schedule IDs, seeded like the random decisions
+	orderSeed    int64          // shuffles the order of bone loads and day blocking, 0 keeps the original order
+	starts       []StartSummary // results of the multi-start generation, nil for a single run
 }
 
 func NewScheduleGenerator(cfg ScheduleGeneratorConfig) (*ScheduleGenerator, error) {
@@ -66,30 +108,62 @@ func NewScheduleGenerator(cfg ScheduleGeneratorConfig) (*ScheduleGenerator, erro
 	if cfg.Start.After(cfg.End) {
 		return nil, fmt.Errorf("start date comes after end")
 	}
+	if cfg.BoneCycleWeeks < 0 {
+		return nil, fmt.Errorf("bone cycle weeks below 0 (%d)", cfg.BoneCycleWeeks)
+	}
+	if err := cfg.Calendar.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid calendar: %s", err.Error())
+	}
+	if err := cfg.Bells.Validate(cfg.WorkLessons); err != nil {
+		return nil, fmt.Errorf("invalid bell schedule: %s", err.Error())
+	}
+	if err := cfg.Annealing.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid annealing config: %s", err.Error())
+	}
+	if err := cfg.Tabu.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid tabu search config: %s", err.Error())
+	}
+	if err := cfg.Genetic.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid genetic config: %s", err.Error())
+	}
+	if err := cfg.ExactBoneWeek.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid exact bone week config: %s", err.Error())
+	}
+	if err := cfg.MultiStart.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid multi-start config: %s", err.Error())
+	}
+	if cfg.FaultParameters == nil {
+		cfg.FaultParameters = components.NewDefaultParameterRegistry()
+	}
+	if err := cfg.FaultParameters.CheckProfile(cfg.FaultProfile); err != nil {
+		return nil, fmt.Errorf("invalid fault profile: %s", err.Error())
+	}
 
 	scheduleGenerator := ScheduleGenerator{
 		ScheduleGeneratorConfig: cfg,
+		random:                  rand.New(rand.NewSource(cfg.Seed)),
+		ids:                     rand.New(rand.NewSource(cfg.Seed)),
 	}
 
-	index := 0
+	scheduleGenerator.calendar = cfg.Calendar.newGridCalendar(cfg.Start, cfg.End)
 	for date := cfg.Start; !date.After(cfg.End); date = date.AddDate(0, 0, 1) {
-		scheduleGenerator.busyGrid = append(scheduleGenerator.busyGrid, make([]float32, len(cfg.WorkLessons[date.Weekday()])))
-		copy(scheduleGenerator.busyGrid[index], cfg.WorkLessons[date.Weekday()])
-		index++
+		weekday := scheduleGenerator.calendar.GetWeekday(len(scheduleGenerator.busyGrid))
+		scheduleGenerator.busyGrid = append(scheduleGenerator.busyGrid, slices.Clone(cfg.WorkLessons[weekday]))
 	}
+	cfg.Calendar.blockDays(scheduleGenerator.busyGrid, scheduleGenerator.calendar)
 
-	for i := range 7 {
+	for i := range scheduleGenerator.cycleDays() {
 		scheduleGenerator.weekData.busyGrid = append(scheduleGenerator.weekData.busyGrid,
-			make([]float32, len(cfg.WorkLessons[i])))
-		copy(scheduleGenerator.weekData.busyGrid[i], cfg.WorkLessons[i])
+			make([]float32, len(cfg.WorkLessons[i%7])))
+		copy(scheduleGenerator.weekData.busyGrid[i], cfg.WorkLessons[i%7])
 	}
 
-	ls, err := services.NewLessonService(cfg.LessonsValue)
+	ls, err := services.NewLessonService(cfg.LessonsValue, nil, scheduleGenerator.ids)
 	if err != nil {
 		return nil, err
 	}
 
-	weekLS, err := services.NewLessonService(cfg.LessonsValue)
+	weekLS, err := services.NewLessonService(cfg.LessonsValue, nil, scheduleGenerator.ids)
 	if err != nil {
 		return nil, err
 	}
@@ -97,40 +171,57 @@ func NewScheduleGenerator(cfg ScheduleGeneratorConfig) (*ScheduleGenerator, erro
 	scheduleGenerator.lessonService = ls
 	scheduleGenerator.weekData.lessonService = weekLS
 
-	scheduleGenerator.errorService = components.NewErrorService()
+	scheduleGenerator.progress = components.NewProgressReporter(nil)
+	scheduleGenerator.errorService = components.NewReportingErrorService(components.NewErrorService(),
+		scheduleGenerator.progress)
 
 	return &scheduleGenerator, nil
 }
 
+// SetProgressListener makes the generator send progress events of every phase and added errors
+// to the listener (l) during GenerateSchedule. Nil disables events.
+func (g *ScheduleGenerator) SetProgressListener(l components.ProgressListener) {
+	g.progress.SetListener(l)
+}
+
+// runComponent runs the component with the progress reporter of the generator.
+func (g *ScheduleGenerator) runComponent(ctx context.Context, c components.GeneratorComponent) {
+	c.SetProgressReporter(g.progress)
+	c.Run(ctx)
+}
+
 func (g *ScheduleGenerator) SetTeachers(teachers []types.Teacher) error {
-	ts, err := services.NewTeacherService(teachers, g.busyGrid)
+	ts, err := services.NewTeacherService(teachers, g.busyGrid, g.calendar)
 	if err != nil {
 		return err
 	}
 
-	weekTS, err := services.NewTeacherService(teachers, g.weekData.busyGrid)
+	weekTS, err := services.NewTeacherService(teachers, g.weekData.busyGrid, g.weekData.calendar)
 	if err != nil {
 		return err
 	}
 
 	g.teacherService = ts
 	g.weekData.teacherService = weekTS
+	g.input.teachers = teachers
 	return nil
 }
 
 func (g *ScheduleGenerator) SetStudentGroups(studentGroups []types.StudentGroup) error {
-	sgs, err := services.NewStudentGroupService(studentGroups, g.MaxStudentWorkload, g.busyGrid)
+	sgs, err := services.NewStudentGroupService(studentGroups, g.MaxStudentWorkload, g.busyGrid, g.calendar)
 	if err != nil {
 		return err
 	}
 
-	weekSGS, err := services.NewStudentGroupService(studentGroups, g.MaxStudentWorkload, g.weekData.busyGrid)
+	weekSGS, err := services.NewStudentGroupService(studentGroups, g.MaxStudentWorkload, g.weekData.busyGrid,
+		g.weekData.calendar)
 	if err != nil {
 		return err
 	}
 
 	g.studentGroupService = sgs
 	g.weekData.studentGroupService = weekSGS
+	g.input.studentGroups = studentGroups
 	return nil
 }
 
@@ -147,6 +238,7 @@ func (g *ScheduleGenerator) SetDisciplines(disciplines []types.Discipline) error
 
 	g.disciplineService = ds
 	g.weekData.disciplineService = weekDS
+	g.input.disciplines = disciplines
 	return nil
 }
 
@@ -163,6 +255,38 @@ func (g *ScheduleGenerator) SetLessonTypes(lTypes []types.LessonType) error {
 
 	g.lessonTypeService = lts
 	g.weekData.lessonTypeService = weekLTS
+	g.input.lessonTypes = lTypes
+	return nil
+}
+
+// SetRooms makes the generator assign a free compatible room to every lesson.
+// It must be called before the schedule generation, because it replaces lesson services.
+func (g *ScheduleGenerator) SetRooms(rooms []types.Room) error {
+	rs, err := services.NewRoomService(rooms, g.busyGrid, g.calendar)
+	if err != nil {
+		return err
+	}
+
+	weekRS, err := services.NewRoomService(rooms, g.weekData.busyGrid, g.weekData.calendar)
+	if err != nil {
+		return err
+	}
+
+	ls, err := services.NewLessonService(g.LessonsValue, rs, g.ids)
+	if err != nil {
+		return err
+	}
+
+	weekLS, err := services.NewLessonService(g.LessonsValue, weekRS, g.ids)
+	if err != nil {
+		return err
+	}
+
+	g.roomService = rs
+	g.weekData.roomService = weekRS
+	g.lessonService = ls
+	g.weekData.lessonService = weekLS
+	g.input.rooms = rooms
 	return nil
 }
 
@@ -189,11 +313,47 @@ func (g *ScheduleGenerator) SetStudyLoads(studyLoads []types.StudyLoad) error {
 
 	g.studyLoadService = sls
 	g.weekData.studyLoadService = weekSLS
+	g.input.studyLoads = studyLoads
+	g.setBoneLoads()
 	return nil
 }
 
-// main function
-func (g *ScheduleGenerator) GenerateSchedule() error {
+// cycleDays returns the number of days in the bone cycle.
+func (g *ScheduleGenerator) cycleDays() int {
+	return 7 * max(1, g.BoneCycleWeeks)
+}
+
+// setBoneLoads fills the study loads of the bone cycle. Every load is repeated as many times
+// as it needs lessons per cycle to cover its hours during the semester, but at least once.
+func (g *ScheduleGenerator) setBoneLoads() {
+	cycleWeeks := g.cycleDays() / 7
+	cycles := (g.calendar.CountWeeks(len(g.busyGrid)) + cycleWeeks - 1) / cycleWeeks
+
+	g.boneLoads = nil
+	for _, load := range g.weekData.studyLoadService.GetAll() {
+		hours := load.Teacher.CountHourDeficitFor(entities.NewTeacherLoadKey(load.Discipline, load.StudentGroup, load.Type))
+		lessons := (hours + g.LessonsValue - 1) / g.LessonsValue
+		count := 1
+		if cycles > 0 {
+			count = max(1, (lessons+cycles-1)/cycles)
+		}
+		for range count {
+			g.boneLoads = append(g.boneLoads, load)
+		}
+	}
+	shuffleOrder(g.orderSeed, g.boneLoads)
+}
+
+// ErrCanceled is returned by GenerateSchedule when its context is done before the generation ends.
+var ErrCanceled = errors.New("schedule generation canceled")
+
+// GenerateSchedule is the main function. It runs all phases of the generation on the set data.
+//
+// The generation stops when the context (ctx) is done. The generator keeps the schedule built so far
+// (the best one if an optimizer has started), the remaining phases are skipped
+// and the error wraps ErrCanceled and the context error.
+// If the generator has collected errors, the ErrorService is returned (joined with the cancellation error).
+func (g *ScheduleGenerator) GenerateSchedule(ctx context.Context) error {
 	if g.studyLoadService == nil {
 		return fmt.Errorf("study loads not set")
 	}
@@ -201,70 +361,299 @@ func (g *ScheduleGenerator) GenerateSchedule() error {
 		return fmt.Errorf("study loads not set")
 	}
 
-	components.NewDayBlocker(g.weekData.studentGroupService.GetAll(), g.errorService).SetDayTypes()
+	if g.MultiStart.IsEnabled() {
+		return g.generateMultiStart(ctx)
+	}
 
-	components.NewBoneGenerator(g.errorService, g.weekData.studyLoadService.GetAll(), g.weekData.lessonService).GenerateBoneLessons()
-	g.buildLessonCarcass()
+	err := g.generate(ctx)
+	if g.errorService.IsClear() {
+		return err
+	}
+	if err != nil {
+		return errors.Join(err, g.errorService)
+	}
+	return g.errorService
+}
 
-	// components.NewMissingLessonAdder(g.errorService, g.studyLoadService.GetAll(), g.lessonService).AddMissingLessons()
+// generate runs the phases one by one. Returns the cancellation error if the context is done.
+//
+// The searches (the exact solver and the optimizers) stop with the context and keep their best result.
+// The day types and the bone week are always complete, the carcass and the missing lessons
+// stop with the context and leave a partial schedule.
+func (g *ScheduleGenerator) generate(ctx context.Context) error {
+	complete := context.WithoutCancel(ctx)
 
-	// improver := components.NewImprover(g.lessonService)
-	// improver.SubmitChanges() // CRUNCH - sets start slots for first lesson
-	// result := true
-	// currentFault := g.ScheduleFault()
-	// for result {
-	// 	if currentFault.Fault() <= 0.000001 {
-	// 		break
-	// 	}
-	// 	fault := g.ScheduleFault()
-	// 	if fault.Fault() < currentFault.Fault() {
-	// 		improver.SubmitChanges()
-	// 	}
-	// 	result = improver.ImproveToNext()
-	// }
+	g.runComponent(complete, components.NewDayBlocker(g.dayBlockerGroups(), g.errorService))
+
+	if g.Genetic.IsEnabled() {
+		g.applyBoneWeek(complete, g.evolveBoneWeek(ctx))
+	} else {
+		g.runComponent(ctx, g.newBoneGenerator(g.errorService, g.boneLoads))
+	}
+	g.buildLessonCarcass(ctx)
+	if ctx.Err() != nil {
+		return g.canceledError(ctx)
+	}
 
-	if !g.errorService.IsClear() {
-		return g.errorService
+	g.runComponent(ctx,
+		components.NewMissingLessonAdder(g.errorService, g.studyLoadService.GetAll(), g.lessonService))
+	if ctx.Err() != nil {
+		return g.canceledError(ctx)
 	}
+
+	// the genetic optimizer has already run on the bone week, only the improvers are left
+	improvers := []components.OptimizerComponent{}
+	if g.Annealing.IsEnabled() {
+		improvers = append(improvers,
+			components.NewAnnealer(g.errorService, g.Annealing, g.lessonService, g.ScheduleFault, g.random.Int63()))
+	}
+	if g.Tabu.IsEnabled() {
+		improvers = append(improvers,
+			components.NewTabuSearcher(g.errorService, g.Tabu, g.lessonService, g.ScheduleFault, g.random.Int63()))
+	}
+	// a canceled optimizer leaves the best schedule it has found
+	for _, improver := range improvers {
+		g.runComponent(ctx, improver)
+		g.optimizers = append(g.optimizers, improver)
+		if ctx.Err() != nil {
+			return g.canceledError(ctx)
+		}
+	}
+
 	return nil
 }
 
-func (g *ScheduleGenerator) buildLessonCarcass() {
+// canceledError returns the error of the generation stopped by the context.
+func (g *ScheduleGenerator) canceledError(ctx context.Context) error {
+	return fmt.Errorf("%w: %w", ErrCanceled, ctx.Err())
+}
+
+// newBoneGenerator creates the BoneGenerator selected by the config for the bone week lesson service.
+func (g *ScheduleGenerator) newBoneGenerator(es components.ErrorService, loads []*entities.UnassignedLesson) components.BoneGenerator {
+	if g.ExactBoneWeek.IsEnabled() {
+		return components.NewExactBoneGenerator(es, g.ExactBoneWeek, loads, g.weekData.lessonService)
+	}
+	return components.NewBoneGenerator(es, loads, g.weekData.lessonService)
+}
+
+// newInstance creates an independent generator with the same config and input, but the seed (seed)
+// of its random decisions and IDs. The order of bone loads and day blocking is shuffled by the order seed (orderSeed),
+// 0 keeps the original order.
+func (g *ScheduleGenerator) newInstance(seed, orderSeed int64) (*ScheduleGenerator, error) {
+	cfg := g.ScheduleGeneratorConfig
+	cfg.Seed = seed
+	instance, err := NewScheduleGenerator(cfg)
+	if err != nil {
+		return nil, err
+	}
+	instance.orderSeed = orderSeed
+
+	if err := instance.SetTeachers(g.input.teachers); err != nil {
+		return nil, err
+	}
+	if err := instance.SetStudentGroups(g.input.studentGroups); err != nil {
+		return nil, err
+	}
+	if err := instance.SetDisciplines(g.input.disciplines); err != nil {
+		return nil, err
+	}
+	if err := instance.SetLessonTypes(g.input.lessonTypes); err != nil {
+		return nil, err
+	}
+	if err := instance.SetStudyLoads(g.input.studyLoads); err != nil {
+		return nil, err
+	}
+	if g.input.rooms != nil {
+		if err := instance.SetRooms(g.input.rooms); err != nil {
+			return nil, err
+		}
+	}
+	if g.input.pinnedLessons != nil {
+		if err := instance.SetPinnedLessons(g.input.pinnedLessons); err != nil {
+			return nil, err
+		}
+	}
+
+	return instance, nil
+}
+
+// evolveBoneWeek runs the genetic optimizer, starting with the bone week of the BoneGenerator.
+// Each bone week is rated on its own instance after the carcass is built. Returns the best bone week.
+//
+// The evolution stops when the context (ctx) is done, the seed and the rated bone weeks are always complete.
+func (g *ScheduleGenerator) evolveBoneWeek(ctx context.Context) components.BoneWeek {
+	complete := context.WithoutCancel(ctx)
+	seed := components.BoneWeek{}
+	if instance, err := g.newInstance(g.Seed, g.orderSeed); err == nil {
+		components.NewDayBlocker(instance.dayBlockerGroups(), instance.errorService).SetDayTypes(complete)
+		instance.newBoneGenerator(instance.errorService, instance.boneLoads).Run(ctx)
+		seed = instance.getBoneWeek()
+	} else {
+		g.errorService.AddError(components.NewUnexpectedError("can't create generator instance",
+			"ScheduleGenerator", "evolveBoneWeek", err))
+	}
+
+	evaluate := func(bw components.BoneWeek) float64 {
+		instance, err := g.newInstance(g.Seed, g.orderSeed)
+		if err != nil {
+			return math.Inf(1)
+		}
+
+		components.NewDayBlocker(instance.dayBlockerGroups(), instance.errorService).SetDayTypes(complete)
+		instance.applyBoneWeek(complete, bw)
+		instance.buildLessonCarcass(complete)
+		return instance.ScheduleFault().Fault()
+	}
+
+	optimizer := components.NewGeneticOptimizer(g.errorService, g.Genetic, g.boneLoads, seed, evaluate,
+		g.random.Int63())
+	g.runComponent(ctx, optimizer)
+	g.optimizers = append(g.optimizers, optimizer)
+
+	return optimizer.GetBest()
+}
+
+// getBoneWeek returns the slots of bone lessons in the order of bone cycle study loads.
+func (g *ScheduleGenerator) getBoneWeek() components.BoneWeek {
+	loads := g.boneLoads
 	lessons := g.weekData.lessonService.GetAll()
-	for _, lesson := range lessons {
+	used := make([]bool, len(lessons))
+
+	bw := make(components.BoneWeek, len(loads))
+	for i, load := range loads {
+		bw[i] = entities.NewLessonSlot(-1, -1)
+		for j, lesson := range lessons {
+			if !used[j] && lesson.UnassignedLesson == *load {
+				bw[i] = lesson.LessonSlot
+				used[j] = true
+				break
+			}
+		}
+	}
+	return bw
+}
+
+// applyBoneWeek assigns bone lessons to the slots of the bone week.
+// Loads that don't fit their slots are placed by the BoneGenerator.
+func (g *ScheduleGenerator) applyBoneWeek(ctx context.Context, bw components.BoneWeek) {
+	loads := g.boneLoads
+	missing := []*entities.UnassignedLesson{}
+	for i, load := range loads {
+		if i >= len(bw) || g.weekData.lessonService.AssignLesson(*load, bw[i]) != nil {
+			missing = append(missing, load)
+		}
+	}
+
+	g.runComponent(ctx, components.NewBoneGenerator(g.errorService, missing, g.weekData.lessonService))
+}
+
+// GetCalendarShortfall returns carcass lessons that were lost on holidays and shortened days.
+func (g *ScheduleGenerator) GetCalendarShortfall() []CalendarShortfall {
+	return g.shortfall
+}
+
+// GetOptimizers returns the optimizers in the order they ran during GenerateSchedule.
+// Each of them reports its best fault and number of iterations.
+func (g *ScheduleGenerator) GetOptimizers() []components.OptimizerComponent {
+	return g.optimizers
+}
+
+// buildLessonCarcass repeats the bone cycle lessons over the semester. Days are filled one by one,
+// so a load stops at the day its hours are reached. A bone lesson is placed on every day of its cycle week
+// that works as its weekday, so a transferred day takes the lessons of the weekday it works as.
+// Lessons that fall on dates closed by the calendar are collected as the calendar shortfall.
+// Stops when the context (ctx) is done, the remaining days stay empty.
+func (g *ScheduleGenerator) buildLessonCarcass(ctx context.Context) {
+	type boneLesson struct {
+		entities.LessonSlot
+		load *entities.UnassignedLesson
+		key  entities.TeacherLoadKey
+		room *entities.Room
+	}
+
+	lessons := slices.Clone(g.weekData.lessonService.GetAll())
+	slices.SortStableFunc(lessons, func(a, b *entities.Lesson) int {
+		if a.After(b) {
+			return 1
+		} else if b.After(a) {
+			return -1
+		}
+		return 0
+	})
+
+	bones := make([]boneLesson, len(lessons))
+	for i, lesson := range lessons {
 		teacher := g.teacherService.Find(lesson.Teacher.ID)
-		studentGroup := g.studentGroupService.Find(lesson.StudentGroup.ID)
-		for weekday := range 7 {
-			weekLT := lesson.StudentGroup.GetTypeOfDay(weekday)
-			if weekLT != nil {
-				lt := studentGroup.GetTypeOfDay(weekday)
-				if lt == nil {
-					lt := g.lessonTypeService.Find(weekLT.ID)
-					err := studentGroup.BindWeekday(lt, weekday)
-					if err != nil {
-						g.errorService.AddError(components.NewUnexpectedError("can't bind the lesson type to the day",
-							"ScheduleGenerator", "buildLessonCarcass", err))
+		weekGroups := lesson.GetStudentGroups()
+		studentGroups := make([]*entities.StudentGroup, len(weekGroups))
+		for j, weekGroup := range weekGroups {
+			studentGroups[j] = g.studentGroupService.Find(weekGroup.ID)
+			for weekday := range 7 {
+				weekLT := weekGroup.GetTypeOfDay(weekday)
+				if weekLT != nil {
+					lt := studentGroups[j].GetTypeOfDay(weekday)
+					if lt == nil {
+						lt := g.lessonTypeService.Find(weekLT.ID)
+						err := studentGroups[j].BindWeekday(lt, weekday)
+						if err != nil {
+							g.errorService.AddError(components.NewUnexpectedError("can't bind the lesson type to the day",
+								"ScheduleGenerator", "buildLessonCarcass", err))
+						}
 					}
 				}
 			}
 		}
 		discipline := g.disciplineService.Find(lesson.Discipline.ID)
 		lessonType := g.lessonTypeService.Find(lesson.Type.ID)
+		load := entities.NewUnassignedLesson(lessonType, teacher, studentGroups[0], discipline)
+		if lesson.Stream != nil {
+			load.Stream = entities.NewStream(studentGroups)
+		}
+		// the lesson takes the room of the bone lesson every cycle if it is free
+		var room *entities.Room
+		if lesson.Room != nil {
+			room = g.roomService.Find(lesson.Room.ID)
+		}
 
-		currentWeek := 0
-		outOfGrid := false
-		for !outOfGrid {
-			err := g.lessonService.AssignLesson(*entities.NewUnassignedLesson(lessonType, teacher, studentGroup, discipline),
-				entities.NewLessonSlot(lesson.Day+currentWeek*7, lesson.Slot))
-			if _, ok := err.(entities.DayOutError); ok {
-				outOfGrid = true
+		bones[i] = boneLesson{
+			LessonSlot: lesson.LessonSlot,
+			load:       load,
+			key:        entities.NewTeacherLoadKey(discipline, studentGroups[0], lessonType),
+			room:       room,
+		}
+	}
+
+	// weeks of the semester follow the real dates, so a semester may start on any weekday
+	cycleWeeks := g.cycleDays() / 7
+	g.progress.StartPhase(components.CarcassPhase, len(g.busyGrid))
+	defer g.progress.FinishPhase(len(g.busyGrid))
+	for day := range g.busyGrid {
+		if ctx.Err() != nil {
+			return
+		}
+		g.progress.Progress(day)
+		cycleWeek := g.calendar.GetWeek(day) % cycleWeeks
+		weekday := g.calendar.GetWeekday(day)
+		for _, bone := range bones {
+			if bone.Day/7 != cycleWeek || bone.Day%7 != weekday || bone.load.Teacher.IsEnoughLessonsFor(bone.key) {
+				continue
+			}
+			// a failed assignment (a blocked day) skips only this day
+			slot := entities.NewLessonSlot(day, bone.Slot)
+			err := g.lessonService.AssignLessonInRoom(*bone.load, slot, bone.room)
+			if err != nil && g.Calendar.isClosed(slot, g.calendar) {
+				g.shortfall = append(g.shortfall, CalendarShortfall{
+					UnassignedLesson: *bone.load,
+					Date:             g.calendar.GetDate(day),
+					Hours:            g.LessonsValue,
+				})
 			}
-			currentWeek++
 		}
 	}
 }
 
-// Rates schedule fault. Returns ScheduleFault as a result.
+// Rates schedule fault with the parameters of the FaultParameters registry and the weights of the FaultProfile.
+// Returns ScheduleFault as a result.
 // Returns an empty ScheduleFault if an not enough data.
 func (g *ScheduleGenerator) ScheduleFault() (result components.ScheduleFault) {
 	result = components.NewScheduleFault()
@@ -274,32 +663,130 @@ func (g *ScheduleGenerator) ScheduleFault() (result components.ScheduleFault) {
 		return
 	}
 
-	result.AddParameter("teacher_windows", components.NewSimpleScheduleParameter(
-		float64(g.teacherService.CountWindows()), 0.1,
-	))
-	result.AddParameter("student_group_windows", components.NewSimpleScheduleParameter(
-		float64(g.studentGroupService.CountWindows()), 1000,
-	))
-	result.AddParameter("teacher_hours_deficit", components.NewSimpleScheduleParameter(
-		float64(g.teacherService.CountHourDeficit()), 10,
-	))
-	result.AddParameter("student_group_hours_deficit", components.NewSimpleScheduleParameter(
-		float64(g.studentGroupService.CountHourDeficit()), 10,
-	))
-	result.AddParameter("teacher_lesson_overlapping", components.NewSimpleScheduleParameter(
-		float64(g.teacherService.CountLessonOverlapping()), 10,
-	))
-	result.AddParameter("student_group_lesson_overlapping", components.NewSimpleScheduleParameter(
-		float64(g.studentGroupService.CountLessonOverlapping()), 10,
-	))
-	result.AddParameter("student_group_overtime_lessons", components.NewSimpleScheduleParameter(
-		float64(g.studentGroupService.CountOvertimeLessons()), 10,
-	))
-	result.AddParameter("student_group_invalid_lessons_by_type", components.NewSimpleScheduleParameter(
-		float64(g.studentGroupService.CountInvalidLessonsByType()), 10,
-	))
-
-	return
+	return g.FaultParameters.Build(components.FaultContext{
+		Teachers:      g.teacherService,
+		StudentGroups: g.studentGroupService,
+		Lessons:       g.lessonService,
+	}, g.FaultProfile)
+}
+
+// GetLessonTime returns the start and the end time of the lesson in the semester time zone.
+//
+// Returns an error if the bell schedule isn't set or has no times for the lesson slot.
+func (g *ScheduleGenerator) GetLessonTime(l *entities.Lesson) (start, end time.Time, err error) {
+	if !g.Bells.IsSet() {
+		return start, end, fmt.Errorf("bell schedule not set")
+	}
+
+	return g.Bells.getTimes(l.LessonSlot, g.calendar, g.bellLocation())
+}
+
+// GetLessonSlot returns the slot of the semester grid that starts at the time (start).
+//
+// Returns an error if the bell schedule isn't set, the time is outside of the semester or no slot starts at it.
+func (g *ScheduleGenerator) GetLessonSlot(start time.Time) (entities.LessonSlot, error) {
+	if !g.Bells.IsSet() {
+		return entities.LessonSlot{}, fmt.Errorf("bell schedule not set")
+	}
+
+	slot, err := g.Bells.getSlot(start, g.calendar, g.bellLocation())
+	if err != nil {
+		return entities.LessonSlot{}, err
+	}
+	if slot.Day < 0 || slot.Day >= len(g.busyGrid) {
+		return entities.LessonSlot{}, fmt.Errorf("%s is outside of the semester", start.Format(time.DateTime))
+	}
+	return slot, nil
+}
+
+// bellLocation returns the time zone of the bell schedule.
+func (g *ScheduleGenerator) bellLocation() *time.Location {
+	if g.Bells.Location == nil {
+		return g.Start.Location()
+	}
+	return g.Bells.Location
+}
+
+// ToLessonModel converts the generated lesson to the database model with concrete times.
+//
+// Returns an error if the lesson time can't be found.
+func (g *ScheduleGenerator) ToLessonModel(l *entities.Lesson) (types.Lesson, error) {
+	start, end, err := g.GetLessonTime(l)
+	if err != nil {
+		return types.Lesson{}, fmt.Errorf("lesson %d/%d has no time: %s", l.Day, l.Slot, err.Error())
+	}
+
+	lesson := types.Lesson{
+		ID:        l.ID,
+		StartTime: start,
+		EndTime:   end,
+		Value:     l.Value,
+		Type: types.LessonType{
+			ID:          l.Type.ID,
+			Name:        l.Type.Name,
+			Weeks:       l.Type.Weeks,
+			Value:       l.Type.Value,
+			RoomType:    l.Type.RoomType,
+			DayRequired: l.Type.DayRequired,
+		},
+		TeacherID:    l.Teacher.ID,
+		DisciplineID: l.Discipline.ID,
+	}
+	for _, sg := range l.GetStudentGroups() {
+		lesson.StudentGroupIDs = append(lesson.StudentGroupIDs, sg.ID)
+	}
+	return lesson, nil
+}
+
+// ExportSchedule converts the generated schedule to the database model with the config and the fault
+// that produced it. Lessons have times only if the bell schedule is set.
+//
+// Returns an error if the config can't be encoded or the lesson time can't be found.
+func (g *ScheduleGenerator) ExportSchedule() (types.GeneratedSchedule, error) {
+	config, err := json.Marshal(g.ScheduleGeneratorConfig)
+	if err != nil {
+		return types.GeneratedSchedule{}, fmt.Errorf("can't encode config: %s", err.Error())
+	}
+
+	fault := g.ScheduleFault()
+	schedule := types.GeneratedSchedule{
+		Model:  types.Model{ID: uuid.Must(uuid.NewRandomFromReader(g.ids))},
+		Config: config,
+		Fault:  fault.Fault(),
+		Faults: map[string]float64{},
+	}
+	for _, parameter := range fault.GetBreakdown() {
+		schedule.Faults[parameter.Name] = parameter.Fault
+	}
+
+	for _, l := range g.lessonService.GetAll() {
+		lesson := types.GeneratedLesson{
+			ID:           l.ID,
+			ScheduleID:   schedule.ID,
+			TeacherID:    l.Teacher.ID,
+			DisciplineID: l.Discipline.ID,
+			LessonTypeID: l.Type.ID,
+			Day:          l.Day,
+			Slot:         l.Slot,
+			Value:        l.Value,
+			Pinned:       l.Pinned,
+		}
+		for _, sg := range l.GetStudentGroups() {
+			lesson.StudentGroupIDs = append(lesson.StudentGroupIDs, sg.ID)
+		}
+		if l.Room != nil {
+			lesson.RoomID = l.Room.ID
+		}
+		if g.Bells.IsSet() {
+			lesson.StartTime, lesson.EndTime, err = g.GetLessonTime(l)
+			if err != nil {
+				return types.GeneratedSchedule{}, fmt.Errorf("lesson %d/%d has no time: %s", l.Day, l.Slot, err.Error())
+			}
+		}
+		schedule.Lessons = append(schedule.Lessons, lesson)
+	}
+
+	return schedule, nil
 }
 
 func (g *ScheduleGenerator) WriteSchedule() {
@@ -326,19 +813,68 @@ func (g *ScheduleGenerator) WriteSchedule() {
 		}
 	}
 
+	rSchedule := map[*entities.Room]*entities.PersonalSchedule{}
+	if g.roomService != nil {
+		for _, r := range g.roomService.GetAll() {
+			rSchedule[r] = &entities.PersonalSchedule{
+				BusyGrid: &r.BusyGrid,
+				Out:      "schedule/" + r.Name + ".txt",
+			}
+		}
+	}
+
 	for _, l := range g.lessonService.GetAll() {
 		tSchedule[l.Teacher].InsertLesson(l)
-		sgSchedule[l.StudentGroup].InsertLesson(l)
+		for _, sg := range l.GetStudentGroups() {
+			sgSchedule[sg].InsertLesson(l)
+		}
+		if l.Room != nil {
+			rSchedule[l.Room].InsertLesson(l)
+		}
 	}
 
 	for _, ps := range tSchedule {
 		ps.WritePS(func(l *entities.Lesson) string {
-			return fmt.Sprintf("дисципліна: %s, тип: %s, група: %s", l.Discipline.Name, l.Type.Name, l.StudentGroup.Name)
+			return fmt.Sprintf("%sдисципліна: %s, тип: %s, група: %s%s",
+				g.lessonTimeToString(l), l.Discipline.Name, l.Type.Name, studentGroupsToString(l), roomToString(l))
 		})
 	}
 	for _, ps := range sgSchedule {
 		ps.WritePS(func(l *entities.Lesson) string {
-			return fmt.Sprintf("дисципліна: %s, тип: %s, викладач: %s", l.Discipline.Name, l.Type.Name, l.Teacher.UserName)
+			return fmt.Sprintf("%sдисципліна: %s, тип: %s, викладач: %s%s",
+				g.lessonTimeToString(l), l.Discipline.Name, l.Type.Name, l.Teacher.UserName, roomToString(l))
+		})
+	}
+	for _, ps := range rSchedule {
+		ps.WritePS(func(l *entities.Lesson) string {
+			return fmt.Sprintf("%sдисципліна: %s, тип: %s, група: %s, викладач: %s",
+				g.lessonTimeToString(l), l.Discipline.Name, l.Type.Name, studentGroupsToString(l), l.Teacher.UserName)
 		})
 	}
 }
+
+// studentGroupsToString returns names of all groups attending the lesson.
+func studentGroupsToString(l *entities.Lesson) string {
+	names := make([]string, 0, 1)
+	for _, sg := range l.GetStudentGroups() {
+		names = append(names, sg.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// lessonTimeToString returns the clock time part of the lesson description, empty if the lesson has no time.
+func (g *ScheduleGenerator) lessonTimeToString(l *entities.Lesson) string {
+	start, end, err := g.GetLessonTime(l)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s-%s, ", start.Format("15:04"), end.Format("15:04"))
+}
+
+// roomToString returns the room part of the lesson description, empty if the lesson has no room.
+func roomToString(l *entities.Lesson) string {
+	if l.Room == nil {
+		return ""
+	}
+	return ", аудиторія: " + l.Room.Name
+}
@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Duckademic/schedule-generator/generator/components"
+	"github.com/Duckademic/schedule-generator/types"
+	"github.com/google/uuid"
+)
+
+// testID returns the same ID for the same number in every run.
+func testID(n int) uuid.UUID {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprint(n)))
+}
+
+// newTestConfig returns the config of a six-week semester with five working days of seven slots.
+func newTestConfig() ScheduleGeneratorConfig {
+	workDay := []float32{0.6, 2, 1.8, 1.6, 1.4, 1.2, 1.0}
+	bells := []SlotTime{}
+	for i := range len(workDay) {
+		start := 8*time.Hour + 30*time.Minute + time.Duration(i)*100*time.Minute
+		bells = append(bells, SlotTime{Start: start, End: start + 80*time.Minute})
+	}
+
+	return ScheduleGeneratorConfig{
+		LessonsValue:       2,
+		Start:              time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC),
+		End:                time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		WorkLessons:        [][]float32{{}, workDay, workDay, workDay, workDay, workDay, {}},
+		MaxStudentWorkload: 4,
+		Bells:              BellSchedule{Slots: bells, Location: time.UTC},
+	}
+}
+
+// newTestTeachers returns four teachers.
+func newTestTeachers() (teachers []types.Teacher) {
+	for i := range 4 {
+		teachers = append(teachers, types.Teacher{Model: types.Model{ID: testID(100 + i)}, UserName: fmt.Sprint("teacher", i)})
+	}
+	return
+}
+
+// newTestStudentGroups returns three student groups.
+func newTestStudentGroups() (studentGroups []types.StudentGroup) {
+	for i := range 3 {
+		studentGroups = append(studentGroups, types.StudentGroup{ID: testID(200 + i), Name: fmt.Sprint("group", i), MilitaryDay: -1})
+	}
+	return
+}
+
+// newTestGenerator creates a generator of the config (cfg) with the test teachers and student groups.
+// Every teacher has a lecture and a practice of its discipline with two of the groups.
+func newTestGenerator(t *testing.T, cfg ScheduleGeneratorConfig) *ScheduleGenerator {
+	t.Helper()
+	g, err := NewScheduleGenerator(cfg)
+	if err != nil {
+		t.Fatalf("NewScheduleGenerator() error = %v", err)
+	}
+
+	teachers := newTestTeachers()
+	studentGroups := newTestStudentGroups()
+	disciplines := []types.Discipline{}
+	for i := range teachers {
+		disciplines = append(disciplines, types.Discipline{ID: testID(300 + i), Name: fmt.Sprint("discipline", i)})
+	}
+	lessonTypes := []types.LessonType{{ID: testID(400), Name: "lecture", Value: 2}, {ID: testID(401), Name: "practice", Value: 2}}
+	studyLoads := []types.StudyLoad{}
+	for i, teacher := range teachers {
+		load := types.StudyLoad{TeacherID: teacher.ID}
+		for j, lessonType := range lessonTypes {
+			load.Disciplines = append(load.Disciplines, types.DisciplineLoad{
+				DisciplineID: disciplines[i].ID,
+				GroupsID:     uuid.UUIDs{studentGroups[(i+j)%len(studentGroups)].ID},
+				LessonTypeID: lessonType.ID,
+				Hours:        12,
+			})
+		}
+		studyLoads = append(studyLoads, load)
+	}
+
+	if err := g.SetTeachers(teachers); err != nil {
+		t.Fatalf("SetTeachers() error = %v", err)
+	}
+	if err := g.SetStudentGroups(studentGroups); err != nil {
+		t.Fatalf("SetStudentGroups() error = %v", err)
+	}
+	if err := g.SetDisciplines(disciplines); err != nil {
+		t.Fatalf("SetDisciplines() error = %v", err)
+	}
+	if err := g.SetLessonTypes(lessonTypes); err != nil {
+		t.Fatalf("SetLessonTypes() error = %v", err)
+	}
+	if err := g.SetStudyLoads(studyLoads); err != nil {
+		t.Fatalf("SetStudyLoads() error = %v", err)
+	}
+	return g
+}
+
+func TestGenerateScheduleIsReproducible(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Seed = 42
+	cfg.Annealing = components.AnnealingConfig{InitialTemperature: 5, CoolingRate: 0.99, MinTemperature: 0.01, MaxIterations: 300}
+	cfg.Tabu = components.TabuConfig{MaxIterations: 30, NeighbourhoodSize: 10, Tenure: 5}
+	cfg.MultiStart = MultiStartConfig{Starts: 3, Workers: 3}
+
+	output := func() []byte {
+		g := newTestGenerator(t, cfg)
+		genErr := g.GenerateSchedule(context.Background())
+
+		schedule, err := g.ExportSchedule()
+		if err != nil {
+			t.Fatalf("ExportSchedule() error = %v", err)
+		}
+		if len(schedule.Lessons) == 0 {
+			t.Fatalf("schedule has no lessons")
+		}
+		breakdown := g.ScheduleFault().GetBreakdown()
+
+		b, err := json.Marshal(struct {
+			Schedule  types.GeneratedSchedule
+			Breakdown []components.ParameterBreakdown
+			Starts    []StartSummary
+			Error     string
+		}{schedule, breakdown, g.GetStarts(), fmt.Sprint(genErr)})
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		return b
+	}
+
+	first := output()
+	for range 3 {
+		if next := output(); !bytes.Equal(first, next) {
+			t.Fatalf("outputs of the same seed differ:\n%s\n%s", first, next)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package generator
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Duckademic/schedule-generator/generator/entities"
+	"github.com/Duckademic/schedule-generator/types"
+	"github.com/google/uuid"
+)
+
+// SetLessons sets the generator with the lessons of a schedule edited by hand instead of the study data.
+// Disciplines, lesson types and study loads are taken from the lessons, so every load has exactly
+// the hours of its lessons (their values, the lesson value of the generator if unset). Lessons take the slots of their start times without the checks of the generator.
+// The teachers and student groups must be set before, the bell schedule must be set.
+//
+// Returns an error if a lesson refers to unknown data or no slot starts at its time.
+func (g *ScheduleGenerator) SetLessons(lessons []types.Lesson) error {
+	if err := g.setLessonStudyData(lessons); err != nil {
+		return err
+	}
+	if err := g.assignEditedLessons(lessons); err != nil {
+		return err
+	}
+
+	g.input.lessons = lessons
+	return nil
+}
+
+// CheckLessonEdits checks the lessons edited by hand (edited) against the rules of teachers and student groups
+// and rates the schedule after the edit. The edited lessons replace the lessons with the same IDs set by SetLessons
+// and take the slots of their start times, the other lessons keep their slots. Every edited lesson is checked
+// against the schedule after the edit, so lessons that exchange slots don't break the rules of each other.
+// The generator itself isn't changed.
+//
+// Returns the edited lessons with the times of their slots and the broken rules. Returns an error if an edited lesson
+// isn't found, has no slot or can't take its slot even without the checks.
+func (g *ScheduleGenerator) CheckLessonEdits(edited []types.Lesson) (types.LessonEditResult, error) {
+	result := types.LessonEditResult{FaultBefore: g.ScheduleFault().Fault()}
+
+	lessons := slices.Clone(g.input.lessons)
+	for _, l := range edited {
+		i := slices.IndexFunc(lessons, func(other types.Lesson) bool { return other.ID == l.ID })
+		if i < 0 {
+			return result, fmt.Errorf("lesson %s not found", l.ID)
+		}
+		lessons[i] = l
+	}
+	kept := slices.DeleteFunc(slices.Clone(lessons), func(l types.Lesson) bool {
+		return slices.ContainsFunc(edited, func(other types.Lesson) bool { return other.ID == l.ID })
+	})
+
+	// the lesson is checked on the schedule with all other lessons at their final slots
+	for i, l := range edited {
+		others := append(slices.Clone(kept), edited[:i]...)
+		others = append(others, edited[i+1:]...)
+		instance, err := g.newEditInstance(lessons, others)
+		if err != nil {
+			return result, fmt.Errorf("can't create generator instance: %s", err.Error())
+		}
+		lesson, err := instance.newEditedLesson(l)
+		if err != nil {
+			return result, fmt.Errorf("lesson %s: %s", l.ID, err.Error())
+		}
+		// a weekday without lessons takes the type of the edited lesson, like it does on the assignment
+		bindPinnedWeekday(&instance.generatorData, lesson)
+		result.Violations = append(result.Violations, checkEditedLesson(l.ID, lesson, instance.LessonsValue)...)
+	}
+
+	instance, err := g.newEditInstance(lessons, kept)
+	if err != nil {
+		return result, fmt.Errorf("can't create generator instance: %s", err.Error())
+	}
+	for _, l := range edited {
+		lesson, err := instance.newEditedLesson(l)
+		if err != nil {
+			return result, fmt.Errorf("lesson %s: %s", l.ID, err.Error())
+		}
+		bindPinnedWeekday(&instance.generatorData, lesson)
+		if err := instance.lessonService.AssignLessonUnchecked(*lesson.load, lesson.slot, lesson.value); err != nil {
+			return result, fmt.Errorf("lesson %s: %s", l.ID, err.Error())
+		}
+
+		l.StartTime, l.EndTime, err = instance.Bells.getTimes(lesson.slot, instance.calendar, instance.bellLocation())
+		if err != nil {
+			return result, fmt.Errorf("lesson %s: %s", l.ID, err.Error())
+		}
+		result.Lessons = append(result.Lessons, l)
+	}
+
+	result.FaultAfter = instance.ScheduleFault().Fault()
+	result.FaultDelta = result.FaultAfter - result.FaultBefore
+	return result, nil
+}
+
+// newEditInstance creates a generator with the config, teachers and student groups of the generator
+// and the study data of the lessons. Only the kept lessons (kept) are assigned.
+func (g *ScheduleGenerator) newEditInstance(lessons, kept []types.Lesson) (*ScheduleGenerator, error) {
+	instance, err := NewScheduleGenerator(g.ScheduleGeneratorConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := instance.SetTeachers(g.input.teachers); err != nil {
+		return nil, err
+	}
+	if err := instance.SetStudentGroups(g.input.studentGroups); err != nil {
+		return nil, err
+	}
+	if err := instance.setLessonStudyData(lessons); err != nil {
+		return nil, err
+	}
+	if err := instance.assignEditedLessons(kept); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// setLessonStudyData sets the disciplines, lesson types and study loads that the lessons cover exactly.
+// A lesson of several student groups is a lesson of a joint load.
+func (g *ScheduleGenerator) setLessonStudyData(lessons []types.Lesson) error {
+	disciplines := []types.Discipline{}
+	lessonTypes := []types.LessonType{}
+	studyLoads := []types.StudyLoad{}
+	for _, l := range lessons {
+		if !slices.ContainsFunc(disciplines, func(d types.Discipline) bool { return d.ID == l.DisciplineID }) {
+			disciplines = append(disciplines, types.Discipline{ID: l.DisciplineID})
+		}
+		if !slices.ContainsFunc(lessonTypes, func(lt types.LessonType) bool { return lt.ID == l.Type.ID }) {
+			lessonTypes = append(lessonTypes, l.Type)
+		}
+
+		i := slices.IndexFunc(studyLoads, func(sl types.StudyLoad) bool { return sl.TeacherID == l.TeacherID })
+		if i < 0 {
+			studyLoads = append(studyLoads, types.StudyLoad{TeacherID: l.TeacherID})
+			i = len(studyLoads) - 1
+		}
+		load := &studyLoads[i]
+		j := slices.IndexFunc(load.Disciplines, func(dl types.DisciplineLoad) bool {
+			return dl.DisciplineID == l.DisciplineID && dl.LessonTypeID == l.Type.ID && sameGroups(dl.GroupsID, l.StudentGroupIDs)
+		})
+		if j < 0 {
+			load.Disciplines = append(load.Disciplines, types.DisciplineLoad{
+				DisciplineID: l.DisciplineID,
+				GroupsID:     l.StudentGroupIDs,
+				LessonTypeID: l.Type.ID,
+				Joint:        len(l.StudentGroupIDs) > 1,
+			})
+			j = len(load.Disciplines) - 1
+		}
+		load.Disciplines[j].Hours += cmp.Or(l.Value, g.LessonsValue)
+	}
+
+	if err := g.SetDisciplines(disciplines); err != nil {
+		return fmt.Errorf("invalid disciplines: %s", err.Error())
+	}
+	if err := g.SetLessonTypes(lessonTypes); err != nil {
+		return fmt.Errorf("invalid lesson types: %s", err.Error())
+	}
+	if err := g.SetStudyLoads(studyLoads); err != nil {
+		return fmt.Errorf("invalid study loads: %s", err.Error())
+	}
+	return nil
+}
+
+// sameGroups returns true if both slices have the same student groups in any order.
+func sameGroups(a, b []uuid.UUID) bool {
+	return len(a) == len(b) && !slices.ContainsFunc(a, func(id uuid.UUID) bool { return !slices.Contains(b, id) })
+}
+
+// assignEditedLessons assigns the lessons in chronological order without the checks of the generator.
+// Lessons bind their weekdays to their types if the weekdays are free. A lesson at a blocked slot is left out,
+// so the same lessons are left out before and after an edit.
+//
+// Returns an error if a lesson has no study load or no slot starts at its time.
+func (g *ScheduleGenerator) assignEditedLessons(lessons []types.Lesson) error {
+	edited := make([]pinnedLesson, len(lessons))
+	for i, l := range lessons {
+		lesson, err := g.newEditedLesson(l)
+		if err != nil {
+			return fmt.Errorf("lesson %s: %s", l.ID, err.Error())
+		}
+		edited[i] = lesson
+	}
+	slices.SortStableFunc(edited, func(a, b pinnedLesson) int {
+		return cmp.Or(cmp.Compare(a.slot.Day, b.slot.Day), cmp.Compare(a.slot.Slot, b.slot.Slot))
+	})
+
+	for _, lesson := range edited {
+		bindPinnedWeekday(&g.generatorData, lesson)
+		g.lessonService.AssignLessonUnchecked(*lesson.load, lesson.slot, lesson.value)
+	}
+	return nil
+}
+
+// newEditedLesson finds the semester study load and the slot of the lesson.
+func (g *ScheduleGenerator) newEditedLesson(l types.Lesson) (pinnedLesson, error) {
+	slot, err := g.GetLessonSlot(l.StartTime)
+	if err != nil {
+		return pinnedLesson{}, err
+	}
+	load := findPinnedLoad(g.studyLoadService.GetAll(), types.GeneratedLesson{
+		TeacherID:       l.TeacherID,
+		StudentGroupIDs: l.StudentGroupIDs,
+		DisciplineID:    l.DisciplineID,
+		LessonTypeID:    l.Type.ID,
+	})
+	if load == nil {
+		return pinnedLesson{}, fmt.Errorf("no study load of teacher %s, discipline %s and lesson type %s for student groups %s",
+			l.TeacherID, l.DisciplineID, l.Type.ID, l.StudentGroupIDs.Strings())
+	}
+	return pinnedLesson{load: load, slot: slot, value: l.Value}, nil
+}
+
+// checkEditedLesson checks the lesson (lesson) of the edited lesson (id) with the lesson checks of its teacher
+// and every student group. A lesson without its own value takes the lesson value (lessonValue).
+// Returns the broken rules.
+func checkEditedLesson(id uuid.UUID, lesson pinnedLesson, lessonValue int) (violations []types.LessonEditViolation) {
+	l := entities.NewLesson(id, *lesson.load, lesson.slot, cmp.Or(lesson.value, lessonValue))
+	newViolation := func(err error) types.LessonEditViolation {
+		violation := types.LessonEditViolation{LessonID: id, Message: err.Error()}
+		var checkErr entities.LessonCheckError
+		if errors.As(err, &checkErr) {
+			violation.Rule = string(checkErr.Rule)
+		}
+		return violation
+	}
+
+	if err := l.Teacher.CheckLesson(l); err != nil {
+		violation := newViolation(err)
+		violation.TeacherID = l.Teacher.ID
+		violations = append(violations, violation)
+	}
+	for _, sg := range l.GetStudentGroups() {
+		if err := sg.CheckLesson(l); err != nil {
+			violation := newViolation(err)
+			violation.StudentGroupID = sg.ID
+			violations = append(violations, violation)
+		}
+	}
+	return
+}
@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Duckademic/schedule-generator/generator/entities"
+	"github.com/Duckademic/schedule-generator/types"
+	"github.com/google/uuid"
+)
+
+// newEditedTestLesson returns a lecture of the teacher (teacher) and the discipline (discipline) with the student group
+// (studentGroup) at the slot of Monday, January 20 2025 in the test config.
+func newEditedTestLesson(id, teacher, discipline, studentGroup, slot int) types.Lesson {
+	cfg := newTestConfig()
+	start := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC).Add(cfg.Bells.Slots[slot].Start)
+	return types.Lesson{
+		ID:              testID(id),
+		StartTime:       start,
+		EndTime:         start.Add(80 * time.Minute),
+		Value:           2,
+		Type:            types.LessonType{ID: testID(400), Name: "lecture", Value: 2},
+		TeacherID:       testID(100 + teacher),
+		StudentGroupIDs: uuid.UUIDs{testID(200 + studentGroup)},
+		DisciplineID:    testID(300 + discipline),
+	}
+}
+
+func TestCheckLessonEdits(t *testing.T) {
+	// the first and the second lesson of group0
+	first := newEditedTestLesson(1, 0, 0, 0, 1)
+	second := newEditedTestLesson(2, 1, 1, 0, 2)
+	swap := func() []types.Lesson {
+		editedFirst, editedSecond := first, second
+		editedFirst.StartTime, editedSecond.StartTime = second.StartTime, first.StartTime
+		editedFirst.EndTime, editedSecond.EndTime = second.EndTime, first.EndTime
+		return []types.Lesson{editedFirst, editedSecond}
+	}
+
+	tests := []struct {
+		name    string
+		lessons []types.Lesson
+		edit    func() []types.Lesson
+		want    []types.LessonEditViolation // violations without messages
+	}{
+		{
+			name:    "swap of lessons of one student group",
+			lessons: []types.Lesson{first, second},
+			edit:    swap,
+		},
+		{
+			name:    "swap with the teacher of the first lesson busy at the second slot",
+			lessons: []types.Lesson{first, second, newEditedTestLesson(3, 0, 2, 1, 2)},
+			edit:    swap,
+			want:    []types.LessonEditViolation{{LessonID: first.ID, Rule: string(entities.BusyRule), TeacherID: testID(100)}},
+		},
+		{
+			name:    "move to a weekday without lessons",
+			lessons: []types.Lesson{first, second},
+			edit: func() []types.Lesson {
+				edited := first
+				edited.StartTime, edited.EndTime = first.StartTime.AddDate(0, 0, 1), first.EndTime.AddDate(0, 0, 1)
+				return []types.Lesson{edited}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g, err := NewScheduleGenerator(newTestConfig())
+			if err != nil {
+				t.Fatalf("NewScheduleGenerator() error = %v", err)
+			}
+			if err := g.SetTeachers(newTestTeachers()); err != nil {
+				t.Fatalf("SetTeachers() error = %v", err)
+			}
+			if err := g.SetStudentGroups(newTestStudentGroups()); err != nil {
+				t.Fatalf("SetStudentGroups() error = %v", err)
+			}
+			if err := g.SetLessons(test.lessons); err != nil {
+				t.Fatalf("SetLessons() error = %v", err)
+			}
+
+			edited := test.edit()
+			result, err := g.CheckLessonEdits(edited)
+			if err != nil {
+				t.Fatalf("CheckLessonEdits() error = %v", err)
+			}
+
+			if len(result.Violations) != len(test.want) {
+				t.Fatalf("CheckLessonEdits() violations = %+v, want %+v", result.Violations, test.want)
+			}
+			for i, violation := range result.Violations {
+				violation.Message = ""
+				if violation != test.want[i] {
+					t.Errorf("violation %d = %+v, want %+v", i, violation, test.want[i])
+				}
+			}
+			for i, lesson := range result.Lessons {
+				if !lesson.StartTime.Equal(edited[i].StartTime) || !lesson.EndTime.Equal(edited[i].EndTime) {
+					t.Errorf("lesson %s at %s-%s, want %s-%s", lesson.ID, lesson.StartTime, lesson.EndTime,
+						edited[i].StartTime, edited[i].EndTime)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/Duckademic/schedule-generator/generator/components"
+	"github.com/Duckademic/schedule-generator/generator/entities"
+)
+
+// MultiStartConfig sets the multi-start generation. Every start is an independent generator instance
+// with its own load and day-blocking order, the schedule with the lowest fault is kept.
+type MultiStartConfig struct {
+	Starts  int // number of independent generations, disabled if below 2
+	Workers int // generations that run at the same time, the number of CPUs if zero
+}
+
+// IsEnabled returns true if the generation has more than one start.
+func (c *MultiStartConfig) IsEnabled() bool {
+	return c.Starts > 1
+}
+
+// Validate checks the multi-start config. Returns an error if it is inconsistent.
+func (c *MultiStartConfig) Validate() error {
+	if c.Starts < 0 {
+		return fmt.Errorf("starts below 0 (%d)", c.Starts)
+	}
+	if c.Workers < 0 {
+		return fmt.Errorf("workers below 0 (%d)", c.Workers)
+	}
+	return nil
+}
+
+// StartSummary is the result of one start of the multi-start generation.
+type StartSummary struct {
+	Start  int     // index of the start
+	Seed   int64   // seed of the start, the first start has the seed of the generator
+	Fault  float64 // fault of the generated schedule
+	Errors int     // number of errors collected by the generator
+	Error  string  // error of the generation, empty if there isn't one
+	Best   bool    // true if the schedule of the start is kept
+}
+
+// GetStarts returns the summaries of every start of the multi-start generation, nil for a single run.
+func (g *ScheduleGenerator) GetStarts() []StartSummary {
+	return g.starts
+}
+
+// generateMultiStart runs the starts concurrently and takes over the data of the start with the lowest fault.
+// The first start keeps the original order and the seed of the generator, the others get seeds
+// drawn from the generator seed, which shuffle the order and feed their random decisions.
+//
+// Returns the error of the kept start.
+func (g *ScheduleGenerator) generateMultiStart(ctx context.Context) error {
+	instances := make([]*ScheduleGenerator, g.MultiStart.Starts)
+	for i := range instances {
+		seed, orderSeed := g.Seed, int64(0)
+		if i > 0 {
+			seed = g.random.Int63()
+			orderSeed = seed
+		}
+		instance, err := g.newInstance(seed, orderSeed)
+		if err != nil {
+			return fmt.Errorf("can't create generator instance: %s", err.Error())
+		}
+		// starts don't run starts of their own
+		instance.MultiStart = MultiStartConfig{}
+		instances[i] = instance
+	}
+
+	workers := g.MultiStart.Workers
+	if workers == 0 {
+		workers = runtime.NumCPU()
+	}
+
+	errs := make([]error, len(instances))
+	g.starts = make([]StartSummary, len(instances))
+	g.progress.StartPhase(components.MultiStartPhase, len(instances))
+	defer g.progress.FinishPhase(len(instances))
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	free := make(chan struct{}, workers)
+	done := 0
+	for i, instance := range instances {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			free <- struct{}{}
+			defer func() { <-free }()
+
+			errs[i] = instance.GenerateSchedule(ctx)
+			summary := StartSummary{
+				Start:  i,
+				Seed:   instance.Seed,
+				Fault:  instance.ScheduleFault().Fault(),
+				Errors: instance.errorService.CountErrors(),
+			}
+			if errs[i] != nil {
+				summary.Error = errs[i].Error()
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			g.starts[i] = summary
+			done++
+			g.progress.ProgressWithFault(done, summary.Fault)
+		}()
+	}
+	wg.Wait()
+
+	// the first start wins ties, so the result is never worse than the original order
+	best := 0
+	for i, summary := range g.starts {
+		if summary.Fault < g.starts[best].Fault {
+			best = i
+		}
+	}
+	g.starts[best].Best = true
+	g.takeOver(instances[best])
+
+	return errs[best]
+}
+
+// takeOver replaces the generated data of the generator with the data of the instance.
+func (g *ScheduleGenerator) takeOver(instance *ScheduleGenerator) {
+	g.generatorData = instance.generatorData
+	g.weekData = instance.weekData
+	g.errorService = instance.errorService
+	g.boneLoads = instance.boneLoads
+	g.optimizers = instance.optimizers
+	g.shortfall = instance.shortfall
+	g.orderSeed = instance.orderSeed
+	g.ids = instance.ids
+}
+
+// shuffleOrder shuffles the slice with the order seed (seed) of the generator, 0 keeps the order.
+// Every call with the same seed and length gives the same order.
+func shuffleOrder[T any](seed int64, s []T) {
+	if seed == 0 {
+		return
+	}
+	random := rand.New(rand.NewSource(seed))
+	random.Shuffle(len(s), func(i, j int) { s[i], s[j] = s[j], s[i] })
+}
+
+// dayBlockerGroups returns the student groups of the bone cycle in the day-blocking order.
+func (g *ScheduleGenerator) dayBlockerGroups() []*entities.StudentGroup {
+	groups := slices.Clone(g.weekData.studentGroupService.GetAll())
+	shuffleOrder(g.orderSeed, groups)
+	return groups
+}
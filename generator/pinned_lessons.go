@@ -0,0 +1,282 @@
+package generator
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/Duckademic/schedule-generator/generator/components"
+	"github.com/Duckademic/schedule-generator/generator/entities"
+	"github.com/Duckademic/schedule-generator/types"
+	"github.com/google/uuid"
+)
+
+// SetPinnedLessons assigns lessons that keep their slots (pins), e.g. lessons of a previous schedule
+// or lessons fixed by hand. The generation places only the remaining hours around them.
+// The pins must be set after the study loads and rooms.
+//
+// Every pin also takes its slot of the bone cycle, so the bone week repeats the pinned lessons.
+// A pin that breaks the rules of the generator isn't assigned, a PinnedLessonError is added to the ErrorService.
+//
+// Returns an error if a pin has no study load or refers to an unknown room.
+func (g *ScheduleGenerator) SetPinnedLessons(pins []types.GeneratedLesson) error {
+	if g.studyLoadService == nil || g.weekData.studyLoadService == nil {
+		return fmt.Errorf("study loads not set")
+	}
+
+	lessons := make([]pinnedLesson, len(pins))
+	for i, pin := range pins {
+		lesson, err := g.newPinnedLesson(pin)
+		if err != nil {
+			return fmt.Errorf("pinned lesson %d/%d: %s", pin.Day, pin.Slot, err.Error())
+		}
+		lessons[i] = lesson
+	}
+
+	bonePins := map[pinnedLesson]int{}
+	for _, lesson := range g.assignPinnedLessons(&g.generatorData, lessons) {
+		if bone, ok := g.getBonePin(lesson); ok {
+			// the bone cycle takes the weekday of the type even if its lessons are placed elsewhere
+			bindPinnedWeekday(&g.weekData, bone)
+			bonePins[bone]++
+		}
+	}
+	g.assignBonePins(bonePins)
+
+	g.input.pinnedLessons = pins
+	return nil
+}
+
+// pinnedLesson is a pin with the study load and the room of the generator data it is assigned to.
+type pinnedLesson struct {
+	load  *entities.UnassignedLesson
+	slot  entities.LessonSlot
+	room  *entities.Room
+	value int // Academic hours of the lesson, 0 - the lesson value of the generator.
+}
+
+// newPinnedLesson finds the semester study load and room of the pin.
+func (g *ScheduleGenerator) newPinnedLesson(pin types.GeneratedLesson) (pinnedLesson, error) {
+	load := findPinnedLoad(g.studyLoadService.GetAll(), pin)
+	if load == nil {
+		return pinnedLesson{}, fmt.Errorf("no study load of teacher %s, discipline %s and lesson type %s for student groups %s",
+			pin.TeacherID, pin.DisciplineID, pin.LessonTypeID, pin.StudentGroupIDs.Strings())
+	}
+
+	var room *entities.Room
+	if pin.RoomID != uuid.Nil && g.roomService != nil {
+		room = g.roomService.Find(pin.RoomID)
+		if room == nil {
+			return pinnedLesson{}, fmt.Errorf("room %s not found", pin.RoomID)
+		}
+	}
+	return pinnedLesson{load: load, slot: entities.NewLessonSlot(pin.Day, pin.Slot), room: room}, nil
+}
+
+// findPinnedLoad returns the study load of the pin, nil if there isn't one.
+func findPinnedLoad(loads []*entities.UnassignedLesson, pin types.GeneratedLesson) *entities.UnassignedLesson {
+	for _, load := range loads {
+		if load.Teacher.ID != pin.TeacherID || load.Discipline.ID != pin.DisciplineID || load.Type.ID != pin.LessonTypeID {
+			continue
+		}
+		groups := load.GetStudentGroups()
+		if len(groups) != len(pin.StudentGroupIDs) {
+			continue
+		}
+		if !slices.ContainsFunc(groups, func(sg *entities.StudentGroup) bool {
+			return !slices.Contains(pin.StudentGroupIDs, sg.ID)
+		}) {
+			return load
+		}
+	}
+	return nil
+}
+
+// assignPinnedLessons pins the lessons in chronological order. A lesson may need the next one to avoid a window,
+// so the lessons that fail are retried while any lesson is assigned. Lessons bind their weekdays to their type
+// if the weekdays are free, the binding stays even if the lesson fails.
+//
+// Returns the assigned lessons. A PinnedLessonError is added for every lesson that isn't assigned.
+func (g *ScheduleGenerator) assignPinnedLessons(data *generatorData, lessons []pinnedLesson) (assigned []pinnedLesson) {
+	left := slices.Clone(lessons)
+	slices.SortStableFunc(left, func(a, b pinnedLesson) int {
+		return cmp.Or(cmp.Compare(a.slot.Day, b.slot.Day), cmp.Compare(a.slot.Slot, b.slot.Slot))
+	})
+
+	errs := make([]error, len(left))
+	for progress := true; progress && len(left) != 0; {
+		progress = false
+		failed := left[:0]
+		errs = errs[:0]
+		for _, lesson := range left {
+			err := bindPinnedWeekday(data, lesson)
+			if err == nil {
+				err = data.lessonService.PinLesson(*lesson.load, lesson.slot, lesson.room)
+			}
+			if err != nil {
+				failed = append(failed, lesson)
+				errs = append(errs, err)
+				continue
+			}
+			assigned = append(assigned, lesson)
+			progress = true
+		}
+		left = failed
+	}
+
+	// only the semester lessons are reported, a bone pin that fails is placed by the bone generator
+	if data == &g.generatorData {
+		for i, lesson := range left {
+			g.errorService.AddError(&PinnedLessonError{UnassignedLesson: *lesson.load, Slot: lesson.slot, err: errs[i]})
+		}
+	}
+	return
+}
+
+// bindPinnedWeekday binds the weekday of the lesson to its type for all student groups of the lesson.
+// Weekdays that are bound to another type are left, the assignment reports them.
+func bindPinnedWeekday(data *generatorData, lesson pinnedLesson) error {
+	weekday := data.calendar.GetWeekday(lesson.slot.Day)
+	if weekday < 0 {
+		return fmt.Errorf("day %d is outside of the grid", lesson.slot.Day)
+	}
+	for _, sg := range lesson.load.GetStudentGroups() {
+		if sg.GetTypeOfDay(weekday) != nil {
+			continue
+		}
+		if err := sg.BindWeekday(lesson.load.Type, weekday); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getBonePin returns the pin of the bone cycle for the semester lesson. Returns false if the bone cycle
+// has no study load of the lesson.
+func (g *ScheduleGenerator) getBonePin(lesson pinnedLesson) (pinnedLesson, bool) {
+	groupIDs := uuid.UUIDs{}
+	for _, sg := range lesson.load.GetStudentGroups() {
+		groupIDs = append(groupIDs, sg.ID)
+	}
+	load := findPinnedLoad(g.weekData.studyLoadService.GetAll(), types.GeneratedLesson{
+		TeacherID:       lesson.load.Teacher.ID,
+		StudentGroupIDs: groupIDs,
+		DisciplineID:    lesson.load.Discipline.ID,
+		LessonTypeID:    lesson.load.Type.ID,
+	})
+	if load == nil {
+		return pinnedLesson{}, false
+	}
+
+	var room *entities.Room
+	if lesson.room != nil && g.weekData.roomService != nil {
+		room = g.weekData.roomService.Find(lesson.room.ID)
+	}
+	cycleWeek := g.calendar.GetWeek(lesson.slot.Day) % (g.cycleDays() / 7)
+	day := cycleWeek*7 + g.calendar.GetWeekday(lesson.slot.Day)
+	return pinnedLesson{load: load, slot: entities.NewLessonSlot(day, lesson.slot.Slot), room: room}, true
+}
+
+// assignBonePins pins the bone lessons of the semester pins (count - number of semester pins). The bone loads
+// keep as many lessons of a load as the bone cycle needs besides its pins, so a load gets no more bone pins
+// than it has bone lessons. Slots pinned more often in the semester are taken first.
+func (g *ScheduleGenerator) assignBonePins(count map[pinnedLesson]int) {
+	bones := make([]pinnedLesson, 0, len(count))
+	for bone := range count {
+		bones = append(bones, bone)
+	}
+	loadOrder := func(load *entities.UnassignedLesson) int {
+		return slices.Index(g.weekData.studyLoadService.GetAll(), load)
+	}
+	roomOrder := func(room *entities.Room) string {
+		if room == nil {
+			return ""
+		}
+		return room.ID.String()
+	}
+	slices.SortFunc(bones, func(a, b pinnedLesson) int {
+		return cmp.Or(cmp.Compare(count[b], count[a]), cmp.Compare(loadOrder(a.load), loadOrder(b.load)),
+			cmp.Compare(a.slot.Day, b.slot.Day), cmp.Compare(a.slot.Slot, b.slot.Slot),
+			cmp.Compare(roomOrder(a.room), roomOrder(b.room)))
+	})
+
+	limited := []pinnedLesson{}
+	left := slices.Clone(g.boneLoads)
+	for _, bone := range bones {
+		if i := slices.Index(left, bone.load); i >= 0 {
+			left = slices.Delete(left, i, i+1)
+			limited = append(limited, bone)
+		}
+	}
+
+	for _, bone := range g.assignPinnedLessons(&g.weekData, limited) {
+		i := slices.Index(g.boneLoads, bone.load)
+		g.boneLoads = slices.Delete(g.boneLoads, i, i+1)
+	}
+}
+
+// PinnedLessonError indicates that a pinned lesson can't take its slot.
+type PinnedLessonError struct {
+	entities.UnassignedLesson
+	Slot entities.LessonSlot
+	err  error
+}
+
+func (e *PinnedLessonError) Error() string {
+	return fmt.Sprintf("Pinned lesson %s %s of %s for %s can't take %s: %s.",
+		e.Type.Name, e.Discipline.Name, e.Teacher.UserName, e.StudentGroup.Name, e.Slot.String(), e.err.Error())
+}
+
+func (e *PinnedLessonError) GetTypeOfError() components.GeneratorComponentErrorTypes {
+	return components.PinnedLessonErrorType
+}
+
+// CompareLessons counts how the lessons (current) differ from the lessons of the base schedule (base).
+// Lessons of the same teacher, student groups, discipline and lesson type are kept at the same slot first,
+// the rest are moved, added or removed.
+func CompareLessons(base, current []types.GeneratedLesson) types.ScheduleDiff {
+	type lessonKey struct {
+		teacher, discipline, lessonType uuid.UUID
+		studentGroups                   string
+	}
+	keyOf := func(l types.GeneratedLesson) lessonKey {
+		groups := slices.Clone(l.StudentGroupIDs)
+		slices.SortFunc(groups, func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) })
+		return lessonKey{l.TeacherID, l.DisciplineID, l.LessonTypeID, fmt.Sprint(groups)}
+	}
+	type slotKey struct {
+		lesson lessonKey
+		slot   entities.LessonSlot
+	}
+
+	baseSlots := map[slotKey]int{}
+	baseLessons := map[lessonKey]int{}
+	for _, l := range base {
+		baseSlots[slotKey{keyOf(l), entities.NewLessonSlot(l.Day, l.Slot)}]++
+		baseLessons[keyOf(l)]++
+	}
+
+	diff := types.ScheduleDiff{}
+	currentLessons := map[lessonKey]int{}
+	for _, l := range current {
+		key := slotKey{keyOf(l), entities.NewLessonSlot(l.Day, l.Slot)}
+		if baseSlots[key] > 0 {
+			baseSlots[key]--
+			baseLessons[key.lesson]--
+			diff.Kept++
+			continue
+		}
+		currentLessons[key.lesson]++
+	}
+
+	for key, count := range currentLessons {
+		moved := min(count, baseLessons[key])
+		diff.Moved += moved
+		diff.Added += count - moved
+		baseLessons[key] -= moved
+	}
+	for _, count := range baseLessons {
+		diff.Removed += count
+	}
+	return diff
+}
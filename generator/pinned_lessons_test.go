@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/Duckademic/schedule-generator/types"
+	"github.com/google/uuid"
+)
+
+func TestCompareLessons(t *testing.T) {
+	teacher, discipline, lecture, practice := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	first, second := uuid.New(), uuid.New()
+	lesson := func(lessonType uuid.UUID, day, slot int, groups ...uuid.UUID) types.GeneratedLesson {
+		return types.GeneratedLesson{
+			ID:              uuid.New(),
+			TeacherID:       teacher,
+			DisciplineID:    discipline,
+			LessonTypeID:    lessonType,
+			StudentGroupIDs: groups,
+			Day:             day,
+			Slot:            slot,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		base, current []types.GeneratedLesson
+		want          types.ScheduleDiff
+	}{
+		{
+			name: "empty",
+			want: types.ScheduleDiff{},
+		},
+		{
+			name:    "kept with other IDs",
+			base:    []types.GeneratedLesson{lesson(lecture, 1, 2, first), lesson(lecture, 8, 2, first)},
+			current: []types.GeneratedLesson{lesson(lecture, 8, 2, first), lesson(lecture, 1, 2, first)},
+			want:    types.ScheduleDiff{Kept: 2},
+		},
+		{
+			name:    "joint lesson with groups in another order",
+			base:    []types.GeneratedLesson{lesson(lecture, 1, 2, first, second)},
+			current: []types.GeneratedLesson{lesson(lecture, 1, 2, second, first)},
+			want:    types.ScheduleDiff{Kept: 1},
+		},
+		{
+			name:    "moved",
+			base:    []types.GeneratedLesson{lesson(lecture, 1, 2, first), lesson(lecture, 8, 2, first)},
+			current: []types.GeneratedLesson{lesson(lecture, 1, 2, first), lesson(lecture, 9, 3, first)},
+			want:    types.ScheduleDiff{Kept: 1, Moved: 1},
+		},
+		{
+			name:    "other lesson type is added and removed",
+			base:    []types.GeneratedLesson{lesson(lecture, 1, 2, first)},
+			current: []types.GeneratedLesson{lesson(practice, 1, 2, first)},
+			want:    types.ScheduleDiff{Added: 1, Removed: 1},
+		},
+		{
+			name:    "other student group is added and removed",
+			base:    []types.GeneratedLesson{lesson(lecture, 1, 2, first)},
+			current: []types.GeneratedLesson{lesson(lecture, 1, 2, second)},
+			want:    types.ScheduleDiff{Added: 1, Removed: 1},
+		},
+		{
+			name:    "more lessons than the base",
+			base:    []types.GeneratedLesson{lesson(lecture, 1, 2, first)},
+			current: []types.GeneratedLesson{lesson(lecture, 2, 2, first), lesson(lecture, 3, 2, first)},
+			want:    types.ScheduleDiff{Moved: 1, Added: 1},
+		},
+		{
+			name:    "fewer lessons than the base",
+			base:    []types.GeneratedLesson{lesson(lecture, 1, 2, first), lesson(lecture, 2, 2, first)},
+			current: []types.GeneratedLesson{lesson(lecture, 3, 2, first)},
+			want:    types.ScheduleDiff{Moved: 1, Removed: 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := CompareLessons(test.base, test.current); got != test.want {
+				t.Errorf("CompareLessons() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -2,31 +2,47 @@ package services
 
 import (
 	"fmt"
+	"io"
 	"sort"
 
 	"github.com/Duckademic/schedule-generator/generator/entities"
+	"github.com/google/uuid"
 )
 
 // LessonService aggregates and manages lessons that the generator works with.
 type LessonService interface {
 	GetAll() []*entities.Lesson // Returns a slice with all lessons as pointers.
-	// Assigns a lesson to the selected slot.
+	// Assigns a lesson to the selected slot and a free compatible room.
 	AssignLesson(entities.UnassignedLesson, entities.LessonSlot) error
+	// Assigns a lesson to the selected slot, keeping the room if it is free and compatible.
+	AssignLessonInRoom(entities.UnassignedLesson, entities.LessonSlot, *entities.Room) error
+	// Assigns a lesson like AssignLessonInRoom and pins it, so it can't be moved or swapped.
+	PinLesson(entities.UnassignedLesson, entities.LessonSlot, *entities.Room) error
+	// Assigns a lesson of the value (academic hours, 0 - the lesson value of the service) to the selected slot
+	// without the checks of the teacher and student groups and without a room, e.g. a lesson placed by hand.
+	// Returns an error if the slot is outside of the grid or blocked.
+	AssignLessonUnchecked(ul entities.UnassignedLesson, slot entities.LessonSlot, value int) error
+	// Returns the room the lesson would take at the slot. Returns nil without error if rooms aren't used.
+	FindFreeRoom(entities.UnassignedLesson, entities.LessonSlot) (*entities.Room, error)
 	MoveLessonTo(*entities.Lesson, entities.LessonSlot) error // MoveLessonTo moves lesson to another slot (to).
+	SwapLessons(first, second *entities.Lesson) error         // Exchanges slots of two lessons.
 	GetWeekLessons(int) []*entities.Lesson                    // TODO: collect bone lessons in another structure.
 }
 
 // NewLessonService creates a new LessonService basic instance.
 //
-// It requires a number of academic hours for lessons (lesson value - lv).
+// It requires a number of academic hours for lessons (lesson value - lv), a room service (rs)
+// and a source of random bytes for lesson IDs (ids).
+// If the room service is nil, lessons are assigned without rooms.
+// If the source is nil, lesson IDs are crypto-random, a seeded source gives the same IDs in every run.
 //
 // Returns an error if the lesson value is below or equal to zero.
-func NewLessonService(lv int) (LessonService, error) {
+func NewLessonService(lv int, rs RoomService, ids io.Reader) (LessonService, error) {
 	if lv <= 0 {
 		return nil, fmt.Errorf("lessonValue below/equal to 0 (%d)", lv)
 	}
 
-	ls := lessonService{lessonValue: lv}
+	ls := lessonService{lessonValue: lv, roomService: rs, ids: ids}
 
 	return &ls, nil
 }
@@ -35,29 +51,51 @@ func NewLessonService(lv int) (LessonService, error) {
 type lessonService struct {
 	lessons     []*entities.Lesson
 	lessonValue int
+	roomService RoomService
+	ids         io.Reader
+}
+
+// newID returns the ID of a new lesson.
+func (ls *lessonService) newID() uuid.UUID {
+	if ls.ids == nil {
+		return uuid.New()
+	}
+	return uuid.Must(uuid.NewRandomFromReader(ls.ids))
 }
 
 func (ls *lessonService) GetAll() []*entities.Lesson {
 	return ls.lessons
 }
 func (ls *lessonService) AssignLesson(ul entities.UnassignedLesson, slot entities.LessonSlot) error {
+	return ls.AssignLessonInRoom(ul, slot, nil)
+}
+func (ls *lessonService) AssignLessonInRoom(ul entities.UnassignedLesson, slot entities.LessonSlot, room *entities.Room) error {
 	if err := ul.Validate(); err != nil {
 		return err
 	}
 
-	lesson := entities.NewLesson(ul, slot, ls.lessonValue)
+	lesson := entities.NewLesson(ls.newID(), ul, slot, ls.lessonValue)
 
 	if err := ul.Teacher.CheckLesson(lesson); err != nil {
 		return err
 	}
-	if err := ul.StudentGroup.CheckLesson(lesson); err != nil {
+	for _, studentGroup := range ul.GetStudentGroups() {
+		if err := studentGroup.CheckLesson(lesson); err != nil {
+			return err
+		}
+	}
+	room, err := ls.pickRoom(ul, slot, room)
+	if err != nil {
 		return err
 	}
 
 	ls.lessons = append(ls.lessons, lesson)
+	ls.setRoom(lesson, room)
 
-	if err := lesson.StudentGroup.AddLesson(lesson); err != nil {
-		panic("pass the check before, but error accurse")
+	for _, studentGroup := range lesson.GetStudentGroups() {
+		if err := studentGroup.AddLesson(lesson); err != nil {
+			panic("pass the check before, but error accurse")
+		}
 	}
 	if err := lesson.Teacher.AddLesson(lesson); err != nil {
 		panic("pass the check before, but error accurse")
@@ -65,9 +103,48 @@ func (ls *lessonService) AssignLesson(ul entities.UnassignedLesson, slot entitie
 
 	return nil
 }
+func (ls *lessonService) PinLesson(ul entities.UnassignedLesson, slot entities.LessonSlot, room *entities.Room) error {
+	if err := ls.AssignLessonInRoom(ul, slot, room); err != nil {
+		return err
+	}
+	ls.lessons[len(ls.lessons)-1].Pinned = true
+	return nil
+}
+func (ls *lessonService) AssignLessonUnchecked(ul entities.UnassignedLesson, slot entities.LessonSlot, value int) error {
+	if err := ul.Validate(); err != nil {
+		return err
+	}
+	if ul.Teacher.IsBlocked(slot) {
+		return fmt.Errorf("slot %s is blocked for teacher %s", slot.String(), ul.Teacher.UserName)
+	}
+	for _, studentGroup := range ul.GetStudentGroups() {
+		for _, group := range append([]*entities.StudentGroup{studentGroup}, studentGroup.GetSubgroups()...) {
+			if group.IsBlocked(slot) {
+				return fmt.Errorf("slot %s is blocked for student group %s", slot.String(), group.Name)
+			}
+		}
+	}
+
+	if value <= 0 {
+		value = ls.lessonValue
+	}
+	// a taken slot stays busy, the lesson counts as an overlapping one
+	lesson := entities.NewLesson(ls.newID(), ul, slot, value)
+	ls.lessons = append(ls.lessons, lesson)
+	lesson.Teacher.SetSlotBusyState(slot, true)
+	lesson.Teacher.TeacherLoadService.AddLesson(lesson)
+	for _, studentGroup := range lesson.GetStudentGroups() {
+		studentGroup.SetSlotBusyState(slot, true)
+		studentGroup.StudentLoadService.AddLesson(lesson)
+	}
+	return nil
+}
+func (ls *lessonService) FindFreeRoom(ul entities.UnassignedLesson, slot entities.LessonSlot) (*entities.Room, error) {
+	return ls.pickRoom(ul, slot, nil)
+}
 func (ls *lessonService) GetWeekLessons(week int) (res []*entities.Lesson) {
 	for _, l := range ls.lessons {
-		if l.Day/7 == week {
+		if l.Teacher.Calendar.GetWeek(l.Day) == week {
 			res = append(res, l)
 		}
 	}
@@ -80,19 +157,126 @@ func (ls *lessonService) GetWeekLessons(week int) (res []*entities.Lesson) {
 	return
 }
 func (ls *lessonService) MoveLessonTo(lesson *entities.Lesson, to entities.LessonSlot) error {
+	if lesson.Pinned {
+		return fmt.Errorf("lesson at %s is pinned", lesson.LessonSlot.String())
+	}
 	if err := lesson.Teacher.LessonCanBeMoved(lesson.LessonSlot, to); err != nil {
 		return err
 	}
-	if err := lesson.StudentGroup.LessonCanBeMoved(lesson, to); err != nil {
+	for _, studentGroup := range lesson.GetStudentGroups() {
+		if err := studentGroup.LessonCanBeMoved(lesson, to); err != nil {
+			return err
+		}
+	}
+	room, err := ls.pickRoom(lesson.UnassignedLesson, to, lesson.Room)
+	if err != nil {
 		return err
 	}
 
 	if err := lesson.Teacher.MoveLessonTo(lesson.LessonSlot, to); err != nil {
 		panic("pass the check before, but error accurse")
 	}
-	if err := lesson.StudentGroup.MoveLessonTo(lesson, to); err != nil {
-		panic("pass the check before, but error accurse")
+	for _, studentGroup := range lesson.GetStudentGroups() {
+		if err := studentGroup.MoveLessonTo(lesson, to); err != nil {
+			panic("pass the check before, but error accurse")
+		}
 	}
+	ls.setRoom(lesson, nil)
 	lesson.MoveLessonTo(to)
+	ls.setRoom(lesson, room)
+	return nil
+}
+func (ls *lessonService) SwapLessons(first, second *entities.Lesson) error {
+	if first.LessonSlot == second.LessonSlot {
+		return fmt.Errorf("lessons are at the same slot (%s)", first.LessonSlot.String())
+	}
+	for _, lesson := range []*entities.Lesson{first, second} {
+		if lesson.Pinned {
+			return fmt.Errorf("lesson at %s is pinned", lesson.LessonSlot.String())
+		}
+	}
+
+	firstSlot, secondSlot := first.LessonSlot, second.LessonSlot
+	err := ls.checkSwapMove(first, second)
+	if err == nil {
+		err = ls.checkSwapMove(second, first)
+	}
+
+	ls.setLessonBusyState(first, false)
+	ls.setLessonBusyState(second, false)
+	// lessons take different slots, so their rooms can't conflict with each other
+	var firstRoom, secondRoom *entities.Room
+	if err == nil {
+		firstRoom, err = ls.pickRoom(first.UnassignedLesson, secondSlot, first.Room)
+	}
+	if err == nil {
+		secondRoom, err = ls.pickRoom(second.UnassignedLesson, firstSlot, second.Room)
+	}
+	if err == nil {
+		first.MoveLessonTo(secondSlot)
+		second.MoveLessonTo(firstSlot)
+		first.Room, second.Room = firstRoom, secondRoom
+	}
+
+	ls.setLessonBusyState(first, true)
+	ls.setLessonBusyState(second, true)
+	return err
+}
+
+// setLessonBusyState marks the lesson slot as busy or free in the teacher, student groups and room grids.
+func (ls *lessonService) setLessonBusyState(lesson *entities.Lesson, isBusy bool) {
+	lesson.Teacher.SetSlotBusyState(lesson.LessonSlot, isBusy)
+	for _, studentGroup := range lesson.GetStudentGroups() {
+		studentGroup.SetSlotBusyState(lesson.LessonSlot, isBusy)
+	}
+	if lesson.Room != nil {
+		lesson.Room.SetSlotBusyState(lesson.LessonSlot, isBusy)
+	}
+}
+
+// setRoom releases the current room of the lesson and takes the new one (room) at the lesson slot.
+func (ls *lessonService) setRoom(lesson *entities.Lesson, room *entities.Room) {
+	if lesson.Room != nil {
+		lesson.Room.SetSlotBusyState(lesson.LessonSlot, false)
+	}
+	lesson.Room = room
+	if room != nil {
+		room.SetSlotBusyState(lesson.LessonSlot, true)
+	}
+}
+
+// pickRoom returns the room for the lesson at the slot. The preferred room is kept if it is free and compatible,
+// otherwise the smallest compatible free room is taken.
+//
+// Returns nil without error if rooms aren't used. Returns an error if there is no compatible free room.
+func (ls *lessonService) pickRoom(ul entities.UnassignedLesson, slot entities.LessonSlot, preferred *entities.Room) (*entities.Room, error) {
+	if ls.roomService == nil {
+		return nil, nil
+	}
+
+	roomType, students := ul.Type.RoomType, ul.CountStudents()
+	if preferred != nil && preferred.Fits(roomType, students) && preferred.IsFree(slot) {
+		return preferred, nil
+	}
+	if room := ls.roomService.FindFreeRoom(roomType, students, slot); room != nil {
+		return room, nil
+	}
+	return nil, fmt.Errorf("no free room for %d students of %s at %s", students, ul.StudentGroup.Name, slot.String())
+}
+
+// checkSwapMove checks if the lesson can be moved to the slot of the other lesson (other) like MoveLessonTo does,
+// with the slot of the other lesson released.
+func (ls *lessonService) checkSwapMove(lesson, other *entities.Lesson) error {
+	ls.setLessonBusyState(other, false)
+	defer ls.setLessonBusyState(other, true)
+
+	if err := lesson.Teacher.LessonCanBeMoved(lesson.LessonSlot, other.LessonSlot); err != nil {
+		return err
+	}
+	for _, studentGroup := range lesson.GetStudentGroups() {
+		if err := studentGroup.LessonCanBeMoved(lesson, other.LessonSlot); err != nil {
+			return err
+		}
+	}
 	return nil
 }
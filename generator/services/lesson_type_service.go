@@ -26,6 +26,7 @@ func NewLessonTypeService(lt []types.LessonType) (LessonTypeService, error) {
 			Name:        lt.Name,
 			Weeks:       lt.Weeks,
 			Value:       lt.Value,
+			RoomType:    lt.RoomType,
 			DayRequired: lt.DayRequired,
 		}
 	}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Duckademic/schedule-generator/generator/entities"
+	"github.com/Duckademic/schedule-generator/types"
+	"github.com/google/uuid"
+)
+
+// RoomService aggregates and manages rooms that the generator works with.
+type RoomService interface {
+	Find(uuid.UUID) *entities.Room // Returns a pointer to the room with the given ID.
+	GetAll() []*entities.Room      // Returns a slice with all rooms as pointers, the smallest first.
+	// Returns the smallest room that is free at the slot, has the room type and enough seats for students.
+	// Lessons without room type take general rooms first. Returns nil if there is no such room.
+	FindFreeRoom(roomType string, students int, slot entities.LessonSlot) *entities.Room
+}
+
+// NewRoomService creates a new RoomService basic instance.
+//
+// It requires an array of database rooms (r), a busy grid for them (bg), and the calendar of grid days (cal).
+//
+// Returns an error if any room is an invalid model.
+func NewRoomService(r []types.Room, bg [][]float32, cal *entities.Calendar) (RoomService, error) {
+	rs := roomService{rooms: make([]*entities.Room, len(r))}
+
+	for i := range r {
+		if r[i].Capacity < 0 {
+			return nil, fmt.Errorf("room %s (%s) has capacity below 0 (%d)", r[i].Name, r[i].ID, r[i].Capacity)
+		}
+		rs.rooms[i] = entities.NewRoom(r[i].ID, r[i].Name, r[i].Capacity, r[i].Type, entities.NewBusyGrid(bg, cal))
+	}
+
+	// the smallest fitting room is taken first, so big rooms stay free for big groups
+	slices.SortStableFunc(rs.rooms, func(a, b *entities.Room) int {
+		return a.Capacity - b.Capacity
+	})
+
+	return &rs, nil
+}
+
+// roomService is the basic implementation of the RoomService interface.
+type roomService struct {
+	rooms []*entities.Room
+}
+
+func (rs *roomService) GetAll() []*entities.Room {
+	return rs.rooms
+}
+func (rs *roomService) Find(id uuid.UUID) *entities.Room {
+	for i := range rs.rooms {
+		if rs.rooms[i].ID == id {
+			return rs.rooms[i]
+		}
+	}
+
+	return nil
+}
+func (rs *roomService) FindFreeRoom(roomType string, students int, slot entities.LessonSlot) *entities.Room {
+	var special *entities.Room
+	for _, room := range rs.rooms {
+		if !room.Fits(roomType, students) || !room.IsFree(slot) {
+			continue
+		}
+		if roomType != "" || room.Type == "" {
+			return room
+		}
+		// rooms with a type are kept for lessons that require them
+		if special == nil {
+			special = room
+		}
+	}
+
+	return special
+}
@@ -11,8 +11,8 @@ import (
 // StudentGroupService aggregates and manages student groups that the generator works with.
 type StudentGroupService interface {
 	Find(uuid.UUID) *entities.StudentGroup // Returns a pointer to the student group with the given ID.
-	GetAll() []*entities.StudentGroup      // Returns a slice with all student groups as pointers.
-	CountWindows() int                     // Returns the sum of windows (gaps between busy slots).
+	GetAll() []*entities.StudentGroup      // Returns a slice with all student groups (subgroups too) as pointers.
+	CountWindows() int                     // Returns the sum of windows (gaps between busy slots) of whole groups.
 	CountHourDeficit() int                 // Returns the number of missing study hours.
 	CountLessonOverlapping() int           // Returns the count of overlapping lessons.
 	CountOvertimeLessons() int             // Returns the total number of overtime lessons (above the daily limit).
@@ -23,17 +23,23 @@ type StudentGroupService interface {
 
 // NewStudentGroupService creates a new StudentGroupService basic instance.
 //
-// It requires an array of database student groups (sg), day load limit (dll), and a busy grid for them (bg).
+// It requires an array of database student groups (sg), day load limit (dll), a busy grid for them (bg),
+// and the calendar of grid days (cal).
+// Subgroups (with ParentID) are created after whole groups and inherit their blocked days.
 //
 // Returns an error if any student group is an invalid model.
-func NewStudentGroupService(sg []types.StudentGroup, dl int, bg [][]float32) (StudentGroupService, error) {
+func NewStudentGroupService(sg []types.StudentGroup, dl int, bg [][]float32, cal *entities.Calendar) (StudentGroupService, error) {
 	sgs := studentGroupService{
 		studentGroups: make([]*entities.StudentGroup, len(sg)),
 	}
 
 	for i := range sg {
-		sgs.studentGroups[i] = entities.NewDefaultStudentGroup(sg[i].ID, sg[i].Name, dl, entities.NewBusyGrid(bg))
+		if sg[i].ParentID != uuid.Nil {
+			continue
+		}
+		sgs.studentGroups[i] = entities.NewDefaultStudentGroup(sg[i].ID, sg[i].Name, dl, entities.NewBusyGrid(bg, cal))
 		studentGroup := sgs.studentGroups[i]
+		studentGroup.Size = sg[i].Size
 
 		// set military day by marks slots on this day as blocked
 		md := sg[i].MilitaryDay
@@ -45,6 +51,19 @@ func NewStudentGroupService(sg []types.StudentGroup, dl int, bg [][]float32) (St
 		}
 	}
 
+	// create subgroups
+	for i := range sg {
+		if sg[i].ParentID == uuid.Nil {
+			continue
+		}
+		parent := sgs.Find(sg[i].ParentID)
+		if parent == nil || parent.IsSubgroup() {
+			return nil, fmt.Errorf("can't find parent group %s for subgroup %s (%s)", sg[i].ParentID, sg[i].Name, sg[i].ID)
+		}
+		sgs.studentGroups[i] = entities.NewSubgroup(sg[i].ID, sg[i].Name, parent)
+		sgs.studentGroups[i].Size = sg[i].Size
+	}
+
 	// create connection for student groups
 	for i := range sg {
 		mainGroup := sgs.Find(sg[i].ID)
@@ -70,7 +89,7 @@ func (sgs *studentGroupService) GetAll() []*entities.StudentGroup {
 }
 func (sgs *studentGroupService) Find(id uuid.UUID) *entities.StudentGroup {
 	for i := range sgs.studentGroups {
-		if sgs.studentGroups[i].ID == id {
+		if sgs.studentGroups[i] != nil && sgs.studentGroups[i].ID == id {
 			return sgs.studentGroups[i]
 		}
 	}
@@ -79,7 +98,9 @@ func (sgs *studentGroupService) Find(id uuid.UUID) *entities.StudentGroup {
 }
 func (sgs *studentGroupService) CountWindows() (count int) {
 	for _, g := range sgs.studentGroups {
-		count += g.CountWindows()
+		if !g.IsSubgroup() {
+			count += g.CountWindows()
+		}
 	}
 	return
 }
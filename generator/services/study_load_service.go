@@ -17,6 +17,10 @@ type StudyLoadService interface {
 // It requires an array of database study loads (sl), teacher, student group, discipline,
 // and lesson type services (ts, sgs, ds, and lts).
 //
+// A joint discipline load becomes a single study load of the stream: the teacher's hours are counted once
+// (for the first group), while every group of the stream gets the hours. Groups of the stream are connected,
+// so they share day types.
+//
 // Returns an error if any study load is an invalid model.
 func NewStudyLoadService(
 	sl []types.StudyLoad,
@@ -56,12 +60,28 @@ func NewStudyLoadService(
 				}
 
 				studentGroups[j] = studentGroup
-				sls.loads = append(sls.loads, entities.NewUnassignedLesson(lessonType, teacher, studentGroup, discipline))
+				if !disciplineLoad.Joint {
+					sls.loads = append(sls.loads, entities.NewUnassignedLesson(lessonType, teacher, studentGroup, discipline))
+				}
 			}
 
 			// if err := discipline.AddLoad(teacher, disciplineLoad.Hours, studentGroups, lessonType); err != nil {
 			// 	return err
 			// }
+			if disciplineLoad.Joint && len(studentGroups) != 0 {
+				load := entities.NewUnassignedLesson(lessonType, teacher, studentGroups[0], discipline)
+				if len(studentGroups) > 1 {
+					load.Stream = entities.NewStream(studentGroups)
+					for j := range studentGroups {
+						for _, other := range studentGroups[j+1:] {
+							studentGroups[j].AddConnectedGroup(other)
+						}
+					}
+				}
+				sls.loads = append(sls.loads, load)
+				teacher.AddLoad(entities.NewTeacherLoadKey(discipline, studentGroups[0], lessonType), disciplineLoad.Hours)
+				continue
+			}
 			for _, group := range studentGroups {
 				teacher.AddLoad(entities.NewTeacherLoadKey(discipline, group, lessonType), disciplineLoad.Hours)
 			}
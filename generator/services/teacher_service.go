@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/Duckademic/schedule-generator/generator/entities"
 	"github.com/Duckademic/schedule-generator/types"
@@ -19,14 +20,15 @@ type TeacherService interface {
 
 // NewTeacherService creates a new TeacherService basic instance.
 //
-// It requires an array of database teachers (t) and a busy grid for them (bg).
+// It requires an array of database teachers (t), a busy grid for them (bg), and the calendar of grid days (cal).
+// If the calendar has no dates, unavailable date ranges are ignored.
 //
 // Returns an error if any teacher is an invalid model.
-func NewTeacherService(t []types.Teacher, bg [][]float32) (TeacherService, error) {
+func NewTeacherService(t []types.Teacher, bg [][]float32, cal *entities.Calendar) (TeacherService, error) {
 	ts := teacherService{teachers: make([]*entities.Teacher, 0, len(t))}
 
 	for i := range t {
-		teacher := entities.NewDefaultTeacher(t[i].ID, t[i].UserName, t[i].Priority, entities.NewBusyGrid(bg))
+		teacher := entities.NewDefaultTeacher(t[i].ID, t[i].UserName, t[i].Priority, entities.NewBusyGrid(bg, cal))
 		for _, day := range t[i].BusyDays {
 			err := teacher.BlockWeekDay(int(day))
 			if err != nil {
@@ -35,6 +37,11 @@ func NewTeacherService(t []types.Teacher, bg [][]float32) (TeacherService, error
 				)
 			}
 		}
+		if err := setAvailability(teacher, t[i].Availability); err != nil {
+			return nil, fmt.Errorf("teacher %s (%s) has invalid availability (err: %s)",
+				teacher.UserName, teacher.ID, err.Error(),
+			)
+		}
 
 		// sort in priority order (not necessary now)
 		// success := false
@@ -53,6 +60,35 @@ func NewTeacherService(t []types.Teacher, bg [][]float32) (TeacherService, error
 	return &ts, nil
 }
 
+// setAvailability blocks slots of the teacher grid that are outside of the availability (a).
+// Date ranges are converted to grid days with the calendar of the teacher grid.
+func setAvailability(teacher *entities.Teacher, a types.TeacherAvailability) error {
+	for _, weekday := range a.WeekdaySlots {
+		if err := teacher.LimitWeekDay(weekday.Weekday, weekday.Slots); err != nil {
+			return err
+		}
+	}
+	for _, dates := range a.Unavailable {
+		if dates.From.After(dates.To) {
+			return fmt.Errorf("unavailable range starts (%s) after end (%s)",
+				dates.From.Format(time.DateOnly), dates.To.Format(time.DateOnly))
+		}
+	}
+
+	if !teacher.Calendar.HasDates() {
+		return nil
+	}
+	for _, dates := range a.Unavailable {
+		for day := max(teacher.Calendar.GetDay(dates.From), 0); day <= teacher.Calendar.GetDay(dates.To); day++ {
+			if teacher.CheckDay(day) != nil {
+				break
+			}
+			teacher.BlockFullDay(day)
+		}
+	}
+	return nil
+}
+
 // teacherService is the basic implementation of the TeacherService interface.
 type teacherService struct {
 	teachers []*entities.Teacher
@@ -4,10 +4,9 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"time"
 
-	"github.com/Duckademic/schedule-generator/generator"
 	"github.com/Duckademic/schedule-generator/repositories"
+	"github.com/Duckademic/schedule-generator/services"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"gorm.io/gorm"
@@ -33,11 +32,10 @@ func init() {
 var server JSONAPIServer
 
 func main() {
-	testGeneration()
-	// err := server.Run()
-	// if err != nil {
-	// 	log.Fatal(err.Error())
-	// }
+	err := server.Run()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
 }
 
 func ENVLoad() error {
@@ -55,27 +53,12 @@ func ServerInit(db *gorm.DB) (*JSONAPIServer, error) {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	wl := [][]float32{
-		{},
-		{0.6, 2, 1.8, 1.6, 1.4, 1.2, 1.0},
-		{0.6, 2, 1.8, 1.6, 1.4, 1.2, 1.0},
-		{0.6, 2, 1.8, 1.6, 1.4, 1.2, 1.0},
-		{0.6, 2, 1.8, 1.6, 1.4, 1.2, 1.0},
-		{0.6, 2, 1.8, 1.6, 1.4, 1.2, 1.0},
-		{},
-	}
 	port := os.Getenv("PORT")
 	if port == "" {
 		return nil, fmt.Errorf("port not specified at .env file")
 	}
 
-	server, err := NewJSONAPIServer(fmt.Sprintf("localhost:%s", port), generator.ScheduleGeneratorConfig{
-		LessonsValue:       2,
-		Start:              time.Date(2025, time.January, 19, 0, 0, 0, 0, time.UTC),
-		End:                time.Date(2025, time.May, 31, 0, 0, 0, 0, time.UTC),
-		WorkLessons:        wl,
-		MaxStudentWorkload: 4,
-	}, db)
+	server, err := NewJSONAPIServer(fmt.Sprintf("localhost:%s", port), services.NewDefaultGeneratorConfig(), db)
 
 	if err != nil {
 		return nil, fmt.Errorf("server creation error: %s", err.Error())
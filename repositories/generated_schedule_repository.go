@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/Duckademic/schedule-generator/types"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GeneratedScheduleRepository interface {
+	Repository[types.GeneratedSchedule]
+	GetVersion(int) *types.GeneratedSchedule // returns the schedule with lessons, nil if not found
+	GetLatest() *types.GeneratedSchedule     // returns the last saved schedule with lessons, nil if there isn't one
+}
+
+func NewGeneratedScheduleRepository(db *gorm.DB) (GeneratedScheduleRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database is nil")
+	}
+
+	gsr := generatedScheduleRepository{
+		simpleRepository: simpleRepository[types.GeneratedSchedule]{
+			db: db,
+		},
+	}
+
+	if err := gsr.Migrate(); err != nil {
+		return nil, fmt.Errorf("generated schedule model migration error: %s", err)
+	}
+	if err := db.AutoMigrate(&types.GeneratedLesson{}); err != nil {
+		return nil, fmt.Errorf("generated lesson model migration error: %s", err)
+	}
+
+	return &gsr, nil
+}
+
+type generatedScheduleRepository struct {
+	simpleRepository[types.GeneratedSchedule]
+}
+
+// Create saves the schedule with its lessons as the next version.
+// The table is locked against other writers until the transaction ends,
+// so concurrent saves can't take the same version.
+func (gsr *generatedScheduleRepository) Create(schedule *types.GeneratedSchedule) error {
+	return gsr.db.Transaction(func(tx *gorm.DB) error {
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(&types.GeneratedSchedule{}); err != nil {
+			return fmt.Errorf("can't parse schedule model: %s", err)
+		}
+		err := tx.Exec("LOCK TABLE ? IN SHARE ROW EXCLUSIVE MODE", clause.Table{Name: stmt.Schema.Table}).Error
+		if err != nil {
+			return fmt.Errorf("can't lock schedules: %s", err)
+		}
+
+		var version int
+		err = tx.Model(&types.GeneratedSchedule{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+		if err != nil {
+			return fmt.Errorf("can't get the last schedule version: %s", err)
+		}
+
+		schedule.Version = version + 1
+		if err := tx.Create(schedule).Error; err != nil {
+			return fmt.Errorf("can't save schedule: %s", err)
+		}
+		return nil
+	})
+}
+
+func (gsr *generatedScheduleRepository) GetFirst(id uuid.UUID) *types.GeneratedSchedule {
+	return gsr.getWithLessons("id = ?", id)
+}
+
+func (gsr *generatedScheduleRepository) GetVersion(version int) *types.GeneratedSchedule {
+	return gsr.getWithLessons("version = ?", version)
+}
+
+func (gsr *generatedScheduleRepository) GetLatest() *types.GeneratedSchedule {
+	var schedule types.GeneratedSchedule
+	err := gsr.db.Preload("Lessons").Order("version DESC").First(&schedule).Error
+	if err != nil {
+		return nil
+	}
+	return &schedule
+}
+
+// GetAll returns all schedules without lessons, ordered by version.
+func (gsr *generatedScheduleRepository) GetAll() (schedules []types.GeneratedSchedule) {
+	gsr.db.Order("version").Find(&schedules)
+	return
+}
+
+func (gsr *generatedScheduleRepository) getWithLessons(query string, args ...any) *types.GeneratedSchedule {
+	var schedule types.GeneratedSchedule
+	err := gsr.db.Preload("Lessons").Where(query, args...).First(&schedule).Error
+	if err != nil {
+		return nil
+	}
+	return &schedule
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Duckademic/schedule-generator/repositories"
+	"github.com/Duckademic/schedule-generator/types"
+	"github.com/google/uuid"
+)
+
+type GeneratedScheduleService interface {
+	// Saves the schedule as the next version. The schedule and its lessons get new IDs,
+	// the generator gives the same IDs for the same seed.
+	Save(types.GeneratedSchedule) (*types.GeneratedSchedule, error)
+	Find(uuid.UUID) *types.GeneratedSchedule
+	FindVersion(int) *types.GeneratedSchedule
+	FindLatest() *types.GeneratedSchedule
+	GetAll() []types.GeneratedSchedule // returns schedules without lessons
+}
+
+func NewGORMGeneratedScheduleService(repo repositories.GeneratedScheduleRepository) (GeneratedScheduleService, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repository is nil")
+	}
+
+	return &gormGeneratedScheduleService{repo: repo}, nil
+}
+
+type gormGeneratedScheduleService struct {
+	repo repositories.GeneratedScheduleRepository
+}
+
+func (gss *gormGeneratedScheduleService) Save(schedule types.GeneratedSchedule) (*types.GeneratedSchedule, error) {
+	schedule.ID = uuid.New()
+	schedule.Lessons = slices.Clone(schedule.Lessons)
+	for i := range schedule.Lessons {
+		schedule.Lessons[i].ID = uuid.New()
+		schedule.Lessons[i].ScheduleID = schedule.ID
+	}
+	return &schedule, gss.repo.Create(&schedule)
+}
+
+func (gss *gormGeneratedScheduleService) Find(id uuid.UUID) *types.GeneratedSchedule {
+	return gss.repo.GetFirst(id)
+}
+
+func (gss *gormGeneratedScheduleService) FindVersion(version int) *types.GeneratedSchedule {
+	return gss.repo.GetVersion(version)
+}
+
+func (gss *gormGeneratedScheduleService) FindLatest() *types.GeneratedSchedule {
+	return gss.repo.GetLatest()
+}
+
+func (gss *gormGeneratedScheduleService) GetAll() []types.GeneratedSchedule {
+	return gss.repo.GetAll()
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"time"
+
+	"github.com/Duckademic/schedule-generator/generator"
+)
+
+// NewDefaultGeneratorConfig returns the generator config of the server: the spring semester
+// with seven lessons on working days. Lesson edits map lesson times to slots with its bell schedule.
+func NewDefaultGeneratorConfig() generator.ScheduleGeneratorConfig {
+	workDay := []float32{0.6, 2, 1.8, 1.6, 1.4, 1.2, 1.0}
+
+	// lessons of 80 minutes with 20 minute breaks from 8:30
+	bells := make([]generator.SlotTime, len(workDay))
+	for i := range bells {
+		start := 8*time.Hour + 30*time.Minute + time.Duration(i)*100*time.Minute
+		bells[i] = generator.SlotTime{Start: start, End: start + 80*time.Minute}
+	}
+
+	return generator.ScheduleGeneratorConfig{
+		LessonsValue:       2,
+		Start:              time.Date(2025, time.January, 19, 0, 0, 0, 0, time.UTC),
+		End:                time.Date(2025, time.May, 31, 0, 0, 0, 0, time.UTC),
+		WorkLessons:        [][]float32{{}, workDay, workDay, workDay, workDay, workDay, {}},
+		MaxStudentWorkload: 4,
+		Bells:              generator.BellSchedule{Slots: bells},
+	}
+}
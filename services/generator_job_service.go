@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Duckademic/schedule-generator/generator"
+	"github.com/Duckademic/schedule-generator/generator/components"
+	"github.com/Duckademic/schedule-generator/types"
+	"github.com/google/uuid"
+)
+
+type GeneratorJobService interface {
+	// Starts a generation in the background with teachers and student groups of the current state
+	// and the study data (input). The generated schedule is saved as the next version
+	// with the difference from the pinned version, or from the latest one if nothing is pinned.
+	// The generation is canceled after the time limit of the input if it is set.
+	Start(input types.GeneratorInput) (*types.GeneratorJob, error)
+	// Returns a copy of the job, nil if not found. Finished jobs are kept for an hour,
+	// their schedules stay in the generated schedules.
+	Find(uuid.UUID) *types.GeneratorJob
+	// Cancels the queued or running job, the best schedule found so far is saved.
+	// Returns an error if the job isn't found or has already finished.
+	Cancel(uuid.UUID) error
+	// Returns progress events of the job starting with the index (from), true if the job has finished,
+	// and a channel that is closed when the job gets new events or finishes.
+	//
+	// Returns false if the job isn't found.
+	WatchEvents(id uuid.UUID, from int) (events []components.ProgressEvent, finished bool, changed <-chan struct{}, ok bool)
+}
+
+// finishedJobRetention is the time a finished job and its events are kept after the finish.
+const finishedJobRetention = time.Hour
+
+func NewGeneratorJobService(
+	cfg generator.ScheduleGeneratorConfig, ts TeacherService, sgs StudentGroupService, gss GeneratedScheduleService,
+) GeneratorJobService {
+	return &generatorJobService{
+		cfg:                 cfg,
+		teacherService:      ts,
+		studentGroupService: sgs,
+		scheduleService:     gss,
+		jobs:                map[uuid.UUID]*types.GeneratorJob{},
+		events:              map[uuid.UUID]*jobEvents{},
+		cancels:             map[uuid.UUID]context.CancelFunc{},
+		retention:           finishedJobRetention,
+	}
+}
+
+type generatorJobService struct {
+	cfg                 generator.ScheduleGeneratorConfig
+	teacherService      TeacherService
+	studentGroupService StudentGroupService
+	scheduleService     GeneratedScheduleService
+	mutex               sync.RWMutex
+	jobs                map[uuid.UUID]*types.GeneratorJob
+	events              map[uuid.UUID]*jobEvents
+	cancels             map[uuid.UUID]context.CancelFunc // cancel functions of unfinished jobs
+	retention           time.Duration                    // finished jobs are removed after it
+}
+
+// jobEvents keeps progress events of the job for clients that watch it.
+type jobEvents struct {
+	events  []components.ProgressEvent
+	changed chan struct{} // closed and replaced on every new event and when the job finishes
+}
+
+// notify wakes up the clients that wait for new events. The mutex must be locked.
+func (je *jobEvents) notify() {
+	close(je.changed)
+	je.changed = make(chan struct{})
+}
+
+func (js *generatorJobService) Start(input types.GeneratorInput) (*types.GeneratorJob, error) {
+	gen, err := generator.NewScheduleGenerator(js.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("can't create generator: %s", err.Error())
+	}
+
+	base := js.scheduleService.FindLatest()
+	pinned := slices.Clone(input.Pinned)
+	if input.PinnedVersion != 0 {
+		base = js.scheduleService.FindVersion(input.PinnedVersion)
+		if base == nil {
+			return nil, fmt.Errorf("schedule version %d not found", input.PinnedVersion)
+		}
+		pinned = append(pinned, base.Lessons...)
+	}
+
+	// the state is copied so that changes made during the generation don't affect it
+	teachers := slices.Clone(js.teacherService.GetAll())
+	studentGroups := slices.Clone(js.studentGroupService.GetAll())
+	if err := setGeneratorInput(gen, teachers, studentGroups, input); err != nil {
+		return nil, err
+	}
+	if len(pinned) != 0 {
+		if err := gen.SetPinnedLessons(pinned); err != nil {
+			return nil, fmt.Errorf("invalid pinned lessons: %s", err.Error())
+		}
+	}
+
+	job := types.GeneratorJob{
+		ID:        uuid.New(),
+		Status:    types.GeneratorJobQueued,
+		CreatedAt: time.Now(),
+	}
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if input.TimeLimitSeconds > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), time.Duration(input.TimeLimitSeconds)*time.Second)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	// the stored job is changed by the generation, the caller gets a copy
+	stored := job
+	js.mutex.Lock()
+	js.jobs[job.ID] = &stored
+	js.events[job.ID] = &jobEvents{changed: make(chan struct{})}
+	js.cancels[job.ID] = cancel
+	js.mutex.Unlock()
+
+	gen.SetProgressListener(func(event components.ProgressEvent) {
+		js.mutex.Lock()
+		defer js.mutex.Unlock()
+
+		events := js.events[job.ID]
+		events.events = append(events.events, event)
+		events.notify()
+	})
+	go js.run(ctx, job.ID, gen, base)
+
+	return &job, nil
+}
+
+func (js *generatorJobService) Find(id uuid.UUID) *types.GeneratorJob {
+	js.mutex.RLock()
+	defer js.mutex.RUnlock()
+
+	job, ok := js.jobs[id]
+	if !ok {
+		return nil
+	}
+	result := *job
+	return &result
+}
+
+func (js *generatorJobService) Cancel(id uuid.UUID) error {
+	js.mutex.RLock()
+	defer js.mutex.RUnlock()
+
+	if _, ok := js.jobs[id]; !ok {
+		return fmt.Errorf("job %s not found", id)
+	}
+	cancel, ok := js.cancels[id]
+	if !ok {
+		return fmt.Errorf("job %s has already finished", id)
+	}
+	cancel()
+	return nil
+}
+
+func (js *generatorJobService) WatchEvents(id uuid.UUID, from int) ([]components.ProgressEvent, bool, <-chan struct{}, bool) {
+	js.mutex.RLock()
+	defer js.mutex.RUnlock()
+
+	job, ok := js.jobs[id]
+	if !ok {
+		return nil, false, nil, false
+	}
+	events := js.events[id]
+	finished := job.Status != types.GeneratorJobQueued && job.Status != types.GeneratorJobRunning
+	return slices.Clone(events.events[min(from, len(events.events)):]), finished, events.changed, true
+}
+
+// run generates and saves the schedule, the job is updated on every stage.
+// The base schedule (base) may be nil.
+func (js *generatorJobService) run(
+	ctx context.Context, id uuid.UUID, gen *generator.ScheduleGenerator, base *types.GeneratedSchedule,
+) {
+	js.update(id, func(job *types.GeneratorJob) {
+		job.Status = types.GeneratorJobRunning
+		job.StartedAt = time.Now()
+	})
+
+	status, fault, version, errs := js.generate(ctx, gen, base)
+	starts := make([]types.GeneratorStart, len(gen.GetStarts()))
+	for i, start := range gen.GetStarts() {
+		starts[i] = types.GeneratorStart{
+			Seed:   start.Seed,
+			Fault:  start.Fault,
+			Errors: start.Errors,
+			Error:  start.Error,
+			Best:   start.Best,
+		}
+	}
+
+	js.update(id, func(job *types.GeneratorJob) {
+		job.Status = status
+		job.Fault = fault
+		job.ScheduleVersion = version
+		job.Errors = errs
+		job.Starts = starts
+		job.FinishedAt = time.Now()
+		job.Duration = job.FinishedAt.Sub(job.StartedAt).String()
+	})
+	js.mutex.Lock()
+	js.cancels[id]()
+	delete(js.cancels, id)
+	js.events[id].notify()
+	js.mutex.Unlock()
+
+	time.AfterFunc(js.retention, func() { js.remove(id) })
+}
+
+// remove deletes the finished job and its events.
+func (js *generatorJobService) remove(id uuid.UUID) {
+	js.mutex.Lock()
+	defer js.mutex.Unlock()
+
+	delete(js.jobs, id)
+	delete(js.events, id)
+}
+
+// generate runs the generator and saves the schedule.
+// Errors collected by the generator don't fail the job, the schedule is saved with them.
+// A canceled generation saves the best schedule found so far.
+// The schedule is compared with the base schedule (base) if it isn't nil.
+func (js *generatorJobService) generate(
+	ctx context.Context, gen *generator.ScheduleGenerator, base *types.GeneratedSchedule,
+) (status types.GeneratorJobStatus, fault float64, version int, errs string) {
+	status = types.GeneratorJobDone
+	if err := gen.GenerateSchedule(ctx); err != nil {
+		var generatorErrors components.ErrorService
+		canceled := errors.Is(err, generator.ErrCanceled)
+		if !canceled && !errors.As(err, &generatorErrors) {
+			return types.GeneratorJobFailed, 0, 0, err.Error()
+		}
+		if canceled {
+			status = types.GeneratorJobCanceled
+		}
+		errs = err.Error()
+	}
+
+	schedule, err := gen.ExportSchedule()
+	if err != nil {
+		return types.GeneratorJobFailed, 0, 0, fmt.Sprintf("can't export schedule: %s", err.Error())
+	}
+	if base != nil {
+		diff := generator.CompareLessons(base.Lessons, schedule.Lessons)
+		diff.BaseVersion = base.Version
+		schedule.Diff = &diff
+	}
+	saved, err := js.scheduleService.Save(schedule)
+	if err != nil {
+		return types.GeneratorJobFailed, schedule.Fault, 0, fmt.Sprintf("can't save schedule: %s", err.Error())
+	}
+
+	return status, saved.Fault, saved.Version, errs
+}
+
+func (js *generatorJobService) update(id uuid.UUID, change func(*types.GeneratorJob)) {
+	js.mutex.Lock()
+	defer js.mutex.Unlock()
+
+	change(js.jobs[id])
+}
+
+// setGeneratorInput sets the generator with all data of the generation.
+func setGeneratorInput(
+	gen *generator.ScheduleGenerator, teachers []types.Teacher, studentGroups []types.StudentGroup, input types.GeneratorInput,
+) error {
+	if err := gen.SetTeachers(teachers); err != nil {
+		return fmt.Errorf("invalid teachers: %s", err.Error())
+	}
+	if err := gen.SetStudentGroups(studentGroups); err != nil {
+		return fmt.Errorf("invalid student groups: %s", err.Error())
+	}
+	if err := gen.SetDisciplines(input.Disciplines); err != nil {
+		return fmt.Errorf("invalid disciplines: %s", err.Error())
+	}
+	if err := gen.SetLessonTypes(input.LessonTypes); err != nil {
+		return fmt.Errorf("invalid lesson types: %s", err.Error())
+	}
+	if len(input.Rooms) != 0 {
+		if err := gen.SetRooms(input.Rooms); err != nil {
+			return fmt.Errorf("invalid rooms: %s", err.Error())
+		}
+	}
+	if err := gen.SetStudyLoads(input.StudyLoads); err != nil {
+		return fmt.Errorf("invalid study loads: %s", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Duckademic/schedule-generator/types"
+	"github.com/google/uuid"
+)
+
+// testScheduleService keeps saved schedules in memory.
+type testScheduleService struct {
+	schedules []types.GeneratedSchedule
+}
+
+func (ss *testScheduleService) Save(schedule types.GeneratedSchedule) (*types.GeneratedSchedule, error) {
+	schedule.Version = len(ss.schedules) + 1
+	ss.schedules = append(ss.schedules, schedule)
+	return &schedule, nil
+}
+func (ss *testScheduleService) Find(uuid.UUID) *types.GeneratedSchedule  { return nil }
+func (ss *testScheduleService) FindVersion(int) *types.GeneratedSchedule { return nil }
+func (ss *testScheduleService) FindLatest() *types.GeneratedSchedule     { return nil }
+func (ss *testScheduleService) GetAll() []types.GeneratedSchedule        { return ss.schedules }
+
+func TestGeneratorJobServiceRemovesFinishedJobs(t *testing.T) {
+	js := NewGeneratorJobService(
+		NewDefaultGeneratorConfig(), NewTeacherService(nil), NewStudentGroupService(nil), &testScheduleService{},
+	).(*generatorJobService)
+	js.retention = 50 * time.Millisecond
+
+	job, err := js.Start(types.GeneratorInput{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	finished := false
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		found := js.Find(job.ID)
+		if found == nil {
+			if !finished {
+				t.Fatalf("job removed before it was seen finished")
+			}
+			if _, _, _, ok := js.WatchEvents(job.ID, 0); ok {
+				t.Errorf("events of the removed job are kept")
+			}
+			return
+		}
+		finished = found.Status != types.GeneratorJobQueued && found.Status != types.GeneratorJobRunning
+	}
+	t.Fatalf("finished job isn't removed after the retention")
+}
@@ -2,26 +2,49 @@ package services
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/Duckademic/schedule-generator/generator"
 	"github.com/Duckademic/schedule-generator/types"
 	"github.com/google/uuid"
 )
 
 type LessonService interface {
-	Service[types.Lesson]
-	SwapSlots(firstId, secondId uuid.UUID) error
+	Service[types.Lesson] // Update rejects edits that break the rules of the generator
+	// Moves the lesson to the slot of its start time and sets its value and type if the edit doesn't break
+	// the rules of the generator, or despite the broken rules with the override.
+	// Returns an error if the edit can't be checked.
+	Edit(lesson types.Lesson, override bool) (types.LessonEditResult, error)
+	// Exchanges slots of the lessons like Edit.
+	SwapSlots(firstId, secondId uuid.UUID, override bool) (types.LessonEditResult, error)
 }
 
-type lessonService struct {
-	lessons []types.Lesson
-}
+// NewLessonService creates a LessonService with the lessons. Edits are checked by a generator with the config (cfg)
+// and the teachers and student groups of the services (ts and sgs).
+//
+// Returns an error if the config has no bell schedule, lesson times can't be mapped to slots without it.
+func NewLessonService(
+	lessons []types.Lesson, cfg generator.ScheduleGeneratorConfig, ts TeacherService, sgs StudentGroupService,
+) (LessonService, error) {
+	if !cfg.Bells.IsSet() {
+		return nil, fmt.Errorf("bell schedule not set, lesson edits can't be checked")
+	}
 
-func NewLessonService(lessons []types.Lesson) LessonService {
 	ls := lessonService{
-		lessons: lessons,
+		lessons:             lessons,
+		cfg:                 cfg,
+		teacherService:      ts,
+		studentGroupService: sgs,
 	}
 
-	return &ls
+	return &ls, nil
+}
+
+type lessonService struct {
+	lessons             []types.Lesson
+	cfg                 generator.ScheduleGeneratorConfig
+	teacherService      TeacherService
+	studentGroupService StudentGroupService
 }
 
 func (ls *lessonService) Create(lesson types.Lesson) (*types.Lesson, error) {
@@ -32,16 +55,32 @@ func (ls *lessonService) Create(lesson types.Lesson) (*types.Lesson, error) {
 }
 
 func (ls *lessonService) Update(lesson types.Lesson) error {
+	result, err := ls.Edit(lesson, false)
+	if err != nil {
+		return err
+	}
+	if !result.Applied {
+		messages := make([]string, len(result.Violations))
+		for i, violation := range result.Violations {
+			messages[i] = violation.Message
+		}
+		return fmt.Errorf("lesson %s can't be moved: %s", lesson.ID, strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+func (ls *lessonService) Edit(lesson types.Lesson, override bool) (types.LessonEditResult, error) {
 	l := ls.Find(lesson.ID)
 	if l == nil {
-		return fmt.Errorf("lesson %s not found", lesson.ID)
+		return types.LessonEditResult{}, fmt.Errorf("lesson %s not found", lesson.ID)
 	}
 
-	l.StartTime = lesson.StartTime
-	l.EndTime = lesson.EndTime
-	l.Value = lesson.Value
-	l.Type = lesson.Type
-	return nil
+	edited := *l
+	edited.StartTime = lesson.StartTime
+	edited.EndTime = lesson.EndTime
+	edited.Value = lesson.Value
+	edited.Type = lesson.Type
+	return ls.edit([]types.Lesson{edited}, override)
 }
 
 func (ls *lessonService) Find(lessonId uuid.UUID) *types.Lesson {
@@ -67,23 +106,49 @@ func (ls *lessonService) GetAll() []types.Lesson {
 	return ls.lessons
 }
 
-func (ls *lessonService) SwapSlots(firstId, secondId uuid.UUID) error {
+func (ls *lessonService) SwapSlots(firstId, secondId uuid.UUID, override bool) (types.LessonEditResult, error) {
 	first := ls.Find(firstId)
 	if first == nil {
-		return fmt.Errorf("lesson %s not found (first)", firstId)
+		return types.LessonEditResult{}, fmt.Errorf("lesson %s not found (first)", firstId)
 	}
 
 	second := ls.Find(secondId)
 	if second == nil {
-		return fmt.Errorf("lesson %s not found (second)", firstId)
+		return types.LessonEditResult{}, fmt.Errorf("lesson %s not found (second)", secondId)
 	}
 
-	tmpTime := first.StartTime
-	first.StartTime = second.StartTime
-	second.StartTime = tmpTime
+	editedFirst, editedSecond := *first, *second
+	editedFirst.StartTime, editedSecond.StartTime = second.StartTime, first.StartTime
+	editedFirst.EndTime, editedSecond.EndTime = second.EndTime, first.EndTime
+	return ls.edit([]types.Lesson{editedFirst, editedSecond}, override)
+}
 
-	tmpTime = first.EndTime
-	first.EndTime = second.EndTime
-	second.EndTime = tmpTime
-	return nil
+// edit checks the edited lessons with a generator of the current lessons and replaces the lessons
+// if the edit breaks no rules or the override is set.
+func (ls *lessonService) edit(edited []types.Lesson, override bool) (types.LessonEditResult, error) {
+	gen, err := generator.NewScheduleGenerator(ls.cfg)
+	if err != nil {
+		return types.LessonEditResult{}, fmt.Errorf("can't create generator: %s", err.Error())
+	}
+	if err := gen.SetTeachers(ls.teacherService.GetAll()); err != nil {
+		return types.LessonEditResult{}, fmt.Errorf("invalid teachers: %s", err.Error())
+	}
+	if err := gen.SetStudentGroups(ls.studentGroupService.GetAll()); err != nil {
+		return types.LessonEditResult{}, fmt.Errorf("invalid student groups: %s", err.Error())
+	}
+	if err := gen.SetLessons(ls.lessons); err != nil {
+		return types.LessonEditResult{}, fmt.Errorf("invalid lessons: %s", err.Error())
+	}
+
+	result, err := gen.CheckLessonEdits(edited)
+	if err != nil {
+		return types.LessonEditResult{}, fmt.Errorf("can't check the edit: %s", err.Error())
+	}
+	result.Applied = len(result.Violations) == 0 || override
+	if result.Applied {
+		for _, lesson := range result.Lessons {
+			*ls.Find(lesson.ID) = lesson
+		}
+	}
+	return result, nil
 }
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Duckademic/schedule-generator/generator"
+	"github.com/Duckademic/schedule-generator/types"
+	"github.com/google/uuid"
+)
+
+// newTestLessonService returns a LessonService of the server config with two lectures of one student group
+// on Monday, January 20 2025: the first at the slot 1, the second at the slot 2.
+func newTestLessonService(t *testing.T) (LessonService, []types.Lesson) {
+	t.Helper()
+	cfg := NewDefaultGeneratorConfig()
+
+	teachers := []types.Teacher{}
+	for i := range 2 {
+		teachers = append(teachers, types.Teacher{Model: types.Model{ID: uuid.New()}, UserName: fmt.Sprint("teacher", i)})
+	}
+	group := types.StudentGroup{ID: uuid.New(), Name: "group", MilitaryDay: -1}
+	lecture := types.LessonType{ID: uuid.New(), Name: "lecture", Value: 2}
+
+	lessons := []types.Lesson{}
+	for i, teacher := range teachers {
+		start := time.Date(2025, time.January, 20, 0, 0, 0, 0, time.UTC).Add(cfg.Bells.Slots[i+1].Start)
+		lessons = append(lessons, types.Lesson{
+			ID:              uuid.New(),
+			StartTime:       start,
+			EndTime:         start.Add(80 * time.Minute),
+			Value:           2,
+			Type:            lecture,
+			TeacherID:       teacher.ID,
+			StudentGroupIDs: uuid.UUIDs{group.ID},
+			DisciplineID:    uuid.New(),
+		})
+	}
+
+	ls, err := NewLessonService(
+		append([]types.Lesson{}, lessons...), cfg, NewTeacherService(teachers), NewStudentGroupService([]types.StudentGroup{group}),
+	)
+	if err != nil {
+		t.Fatalf("NewLessonService() error = %v", err)
+	}
+	return ls, lessons
+}
+
+func TestLessonServiceUpdate(t *testing.T) {
+	ls, lessons := newTestLessonService(t)
+
+	// the second lesson moves to the slot 0, right before the first one
+	edited := lessons[1]
+	edited.StartTime, edited.EndTime = edited.StartTime.Add(-200*time.Minute), edited.EndTime.Add(-200*time.Minute)
+	if err := ls.Update(edited); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got := ls.Find(edited.ID); !got.StartTime.Equal(edited.StartTime) {
+		t.Errorf("lesson starts at %s, want %s", got.StartTime, edited.StartTime)
+	}
+}
+
+func TestLessonServiceSwapSlots(t *testing.T) {
+	ls, lessons := newTestLessonService(t)
+
+	result, err := ls.SwapSlots(lessons[0].ID, lessons[1].ID, false)
+	if err != nil {
+		t.Fatalf("SwapSlots() error = %v", err)
+	}
+	if !result.Applied {
+		t.Fatalf("SwapSlots() violations = %+v, want none", result.Violations)
+	}
+	if got := ls.Find(lessons[0].ID); !got.StartTime.Equal(lessons[1].StartTime) {
+		t.Errorf("first lesson starts at %s, want %s", got.StartTime, lessons[1].StartTime)
+	}
+	if got := ls.Find(lessons[1].ID); !got.StartTime.Equal(lessons[0].StartTime) {
+		t.Errorf("second lesson starts at %s, want %s", got.StartTime, lessons[0].StartTime)
+	}
+}
+
+func TestNewLessonServiceRequiresBells(t *testing.T) {
+	cfg := NewDefaultGeneratorConfig()
+	cfg.Bells = generator.BellSchedule{}
+	if _, err := NewLessonService(nil, cfg, NewTeacherService(nil), NewStudentGroupService(nil)); err == nil {
+		t.Fatalf("NewLessonService() without bells error = nil, want an error")
+	}
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,6 +24,7 @@ type DisciplineLoad struct {
 	GroupsID     []uuid.UUID
 	Hours        int
 	LessonTypeID uuid.UUID
+	Joint        bool // all groups attend the same lessons at once (stream lecture)
 }
 
 // ==============================================================
@@ -31,19 +33,40 @@ type StudentGroup struct {
 	ID              uuid.UUID  `json:"id" binding:"required"`
 	Name            string     `json:"name" binding:"required,min=4"`
 	MilitaryDay     int        `json:"military_day" binding:"gte=1,lte=7"`
-	ConnectedGroups uuid.UUIDs `json:"-"` // Groups that share students with this group.
+	Size            int        `json:"size" binding:"gte=0"` // number of students, 0 if unknown
+	ParentID        uuid.UUID  `json:"parent_id"`            // group the subgroup belongs to, uuid.Nil for a whole group
+	ConnectedGroups uuid.UUIDs `json:"-"`                    // Groups that share students with this group.
 	MainGroup       bool       `json:"-"`
 	// Number string // номер групи (32)
 }
 
 type Teacher struct {
 	Model
-	UserName string        `json:"user_name" binding:"required,min=4,max=64" gorm:"type:varchar(64);unique"`
-	Priority int           `json:"priority"`
-	BusyDays pq.Int64Array `json:"busy_days" gorm:"type:integer[]"`
+	UserName     string              `json:"user_name" binding:"required,min=4,max=64" gorm:"type:varchar(64);unique"`
+	Priority     int                 `json:"priority"`
+	BusyDays     pq.Int64Array       `json:"busy_days" gorm:"type:integer[]"`
+	Availability TeacherAvailability `json:"availability" gorm:"serializer:json"`
 	// AcademicDegree string // асистент/доцент/професор
 }
 
+// TeacherAvailability describes partial availability of the teacher in addition to BusyDays.
+type TeacherAvailability struct {
+	WeekdaySlots []WeekdaySlots `json:"weekday_slots" binding:"dive"` // only these slots are available on the weekday
+	Unavailable  []DateRange    `json:"unavailable" binding:"dive"`   // dates when the teacher can't give lessons
+}
+
+// WeekdaySlots lists available slot indexes (from 0) of the weekday (0 - Sunday).
+type WeekdaySlots struct {
+	Weekday int   `json:"weekday" binding:"gte=0,lte=6"`
+	Slots   []int `json:"slots" binding:"dive,gte=0"`
+}
+
+// DateRange is a range of dates, both ends are included.
+type DateRange struct {
+	From time.Time `json:"from" binding:"required"`
+	To   time.Time `json:"to" binding:"required,gtefield=From"`
+}
+
 type Discipline struct {
 	ID   uuid.UUID
 	Name string
@@ -51,18 +74,133 @@ type Discipline struct {
 }
 
 type Lesson struct {
-	ID        uuid.UUID  `json:"id" validate:"required"`
-	StartTime time.Time  `json:"start_time" binding:"required"`
-	EndTime   time.Time  `json:"end_time" binding:"required"`
-	Value     int        `json:"value" binding:"required,gt=0"` // кількість академічних годин
-	Type      LessonType `json:"type" binding:"required"`
+	ID              uuid.UUID  `json:"id" validate:"required"`
+	StartTime       time.Time  `json:"start_time" binding:"required"`
+	EndTime         time.Time  `json:"end_time" binding:"required"`
+	Value           int        `json:"value" binding:"required,gt=0"` // кількість академічних годин
+	Type            LessonType `json:"type" binding:"required"`
+	TeacherID       uuid.UUID  `json:"teacher_id"`
+	StudentGroupIDs uuid.UUIDs `json:"student_group_ids"` // all groups of a stream lesson
+	DisciplineID    uuid.UUID  `json:"discipline_id"`
 	// Gap       int
 }
 
+// LessonEditResult is the check of lessons edited by hand against the rules of the generator.
+type LessonEditResult struct {
+	Applied     bool                  `json:"applied"` // the edit is valid or applied with an override
+	Violations  []LessonEditViolation `json:"violations,omitempty"`
+	Lessons     []Lesson              `json:"lessons"` // edited lessons with the times of their slots
+	FaultBefore float64               `json:"fault_before"`
+	FaultAfter  float64               `json:"fault_after"`
+	FaultDelta  float64               `json:"fault_delta"` // positive if the edit makes the schedule worse
+}
+
+// LessonEditViolation is a rule of the generator that an edited lesson breaks.
+type LessonEditViolation struct {
+	LessonID       uuid.UUID `json:"lesson_id"`
+	Rule           string    `json:"rule"`             // slot, busy, hours, day_load, window or day_type
+	TeacherID      uuid.UUID `json:"teacher_id"`       // uuid.Nil if a rule of the student group is broken
+	StudentGroupID uuid.UUID `json:"student_group_id"` // uuid.Nil if a rule of the teacher is broken
+	Message        string    `json:"message"`
+}
+
 type LessonType struct {
 	ID          uuid.UUID `json:"id" binding:"required"`
 	Name        string    `json:"name" binding:"required,min=4"`
-	Weeks       []int     `json:"weeks"` // кількість тижнів на початку навчання заповнених тільки цими типами занять
-	Value       int       `json:"value"` // count of hours for one lesson of type LessonType
+	Weeks       []int     `json:"weeks"`     // кількість тижнів на початку навчання заповнених тільки цими типами занять
+	Value       int       `json:"value"`     // count of hours for one lesson of type LessonType
+	RoomType    string    `json:"room_type"` // type of room required for the lesson, any room if empty
 	DayRequired int       `json:"-"`
 }
+
+type Room struct {
+	ID       uuid.UUID `json:"id" binding:"required"`
+	Name     string    `json:"name" binding:"required"`
+	Capacity int       `json:"capacity" binding:"gte=0"` // number of seats
+	Type     string    `json:"type"`                     // lecture hall, computer lab, etc.
+}
+
+// GeneratedSchedule is a schedule produced by the generator. Every saved schedule gets the next version.
+type GeneratedSchedule struct {
+	Model
+	Version int                `json:"version" gorm:"uniqueIndex"`
+	Config  json.RawMessage    `json:"config" gorm:"type:jsonb"` // generator config that produced the schedule
+	Fault   float64            `json:"fault"`
+	Faults  map[string]float64 `json:"faults" gorm:"serializer:json"` // fault of every schedule parameter
+	Lessons []GeneratedLesson  `json:"lessons,omitempty" gorm:"foreignKey:ScheduleID;constraint:OnDelete:CASCADE"`
+	Diff    *ScheduleDiff      `json:"diff,omitempty" gorm:"serializer:json"` // changes since the previous schedule
+}
+
+// ScheduleDiff counts how lessons of a schedule differ from the lessons of the base schedule.
+// Lessons are matched by their teacher, student groups, discipline and lesson type.
+type ScheduleDiff struct {
+	BaseVersion int `json:"base_version"`
+	Kept        int `json:"kept"`    // lessons at the same slot
+	Moved       int `json:"moved"`   // lessons at another slot
+	Added       int `json:"added"`   // lessons that the base schedule doesn't have
+	Removed     int `json:"removed"` // lessons of the base schedule that are gone
+}
+
+// GeneratedLesson is a lesson of the GeneratedSchedule.
+type GeneratedLesson struct {
+	ID              uuid.UUID  `json:"id" gorm:"primary_key"`
+	ScheduleID      uuid.UUID  `json:"schedule_id" gorm:"index"`
+	TeacherID       uuid.UUID  `json:"teacher_id"`
+	StudentGroupIDs uuid.UUIDs `json:"student_group_ids" gorm:"serializer:json"` // all groups of a stream lesson
+	DisciplineID    uuid.UUID  `json:"discipline_id"`
+	LessonTypeID    uuid.UUID  `json:"lesson_type_id"`
+	RoomID          uuid.UUID  `json:"room_id"` // uuid.Nil if the lesson has no room
+	Day             int        `json:"day"`
+	Slot            int        `json:"slot"`
+	StartTime       time.Time  `json:"start_time"` // zero if the generator has no bell schedule
+	EndTime         time.Time  `json:"end_time"`
+	Value           int        `json:"value"`
+	Pinned          bool       `json:"pinned"` // the lesson was fixed before the generation
+}
+
+// GeneratorInput is the study data of a generation that isn't stored by the server.
+type GeneratorInput struct {
+	Disciplines []Discipline `json:"disciplines" binding:"required"`
+	LessonTypes []LessonType `json:"lesson_types" binding:"required"`
+	StudyLoads  []StudyLoad  `json:"study_loads" binding:"required"`
+	Rooms       []Room       `json:"rooms"` // lessons are assigned without rooms if empty
+	// the generation is canceled after the time limit and the best schedule found so far is saved, no limit if zero
+	TimeLimitSeconds int `json:"time_limit_seconds" binding:"min=0"`
+	// all lessons of the saved schedule version keep their slots, no version is pinned if zero
+	PinnedVersion int               `json:"pinned_version" binding:"min=0"`
+	Pinned        []GeneratedLesson `json:"pinned"` // lessons fixed by hand, they keep their slots too
+}
+
+type GeneratorJobStatus string
+
+const (
+	GeneratorJobQueued  GeneratorJobStatus = "queued"
+	GeneratorJobRunning GeneratorJobStatus = "running"
+	GeneratorJobDone    GeneratorJobStatus = "done"   // the schedule is generated and saved
+	GeneratorJobFailed  GeneratorJobStatus = "failed" // the schedule can't be generated or saved
+	// the generation is canceled or out of the time limit, the best schedule found so far is saved
+	GeneratorJobCanceled GeneratorJobStatus = "canceled"
+)
+
+// GeneratorJob is a schedule generation that runs in the background.
+type GeneratorJob struct {
+	ID              uuid.UUID          `json:"id"`
+	Status          GeneratorJobStatus `json:"status"`
+	Fault           float64            `json:"fault"`
+	Errors          string             `json:"errors,omitempty"`           // generator errors, a done job may have them too
+	ScheduleVersion int                `json:"schedule_version,omitempty"` // version of the saved schedule
+	CreatedAt       time.Time          `json:"created_at"`
+	StartedAt       time.Time          `json:"started_at"`
+	FinishedAt      time.Time          `json:"finished_at"`
+	Duration        string             `json:"duration"`         // time from the start to the finish
+	Starts          []GeneratorStart   `json:"starts,omitempty"` // every start of the multi-start generation
+}
+
+// GeneratorStart is the result of one start of the multi-start generation.
+type GeneratorStart struct {
+	Seed   int64   `json:"seed"` // seed of the start, the first start has the seed of the generator
+	Fault  float64 `json:"fault"`
+	Errors int     `json:"errors"` // number of generator errors
+	Error  string  `json:"error,omitempty"`
+	Best   bool    `json:"best"` // the saved schedule is generated by this start
+}